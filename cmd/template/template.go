@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/madkins23/gin-utils/pkg/ginzero"
 	"github.com/madkins23/gin-utils/pkg/handler"
+	"github.com/madkins23/gin-utils/pkg/metrics"
 	"github.com/madkins23/gin-utils/pkg/shutdown"
 	"github.com/madkins23/gin-utils/pkg/system"
 )
@@ -30,6 +32,14 @@ type Config struct {
 	// Configuration object for zerolog.
 	Log logUtils.ConsoleOrFile `json:"log" yaml:"log"`
 
+	// Token required to shut down the server via /exit.
+	// If empty only requests from the local host are allowed.
+	ExitToken string `json:"exitToken" yaml:"exitToken"`
+
+	// Token required in the X-API-Key header to change log levels via PUT /levels.
+	// If empty only requests from the local host are allowed.
+	LevelsToken string `json:"levelsToken" yaml:"levelsToken"`
+
 	// Other configuration items may be added as required.
 }
 
@@ -39,6 +49,8 @@ func main() {
 	var config Config
 	config.Gin.AddFlagsToSet(flags)
 	config.Log.AddFlagsToSet(flags, "/tmp/console-or-file.log")
+	flags.StringVar(&config.ExitToken, "exitToken", "", "token required in X-Exit-Token header for /exit")
+	flags.StringVar(&config.LevelsToken, "levelsToken", "", "token required in X-API-Key header for PUT /levels")
 	if err := flags.Parse(os.Args[1:]); err != nil {
 		if !errors.Is(err, flag.ErrHelp) {
 			fmt.Printf("Error parsing command line flags: %s", err)
@@ -50,6 +62,24 @@ func main() {
 		return
 	}
 	defer config.Log.CloseForDefer()
+	// Allow subsystem log levels lower than the global level.
+	ginzero.HookLevels()
+
+	// Capture stdout and stderr from other libraries after configuring zerolog.
+	capture, err := ginzero.CaptureStd()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Capture process streams")
+	}
+	defer capture.RestoreForDefer()
+	if writer := config.Log.Writer(); writer != nil {
+		// Console log output must go to the original streams so that it isn't captured again.
+		switch writer.Out {
+		case os.Stdout:
+			writer.Out = capture.Stdout()
+		case os.Stderr:
+			writer.Out = capture.Stderr()
+		}
+	}
 
 	// Initialize for graceful shutdown.
 	graceful := &shutdown.Graceful{Config: config.Gin}
@@ -60,20 +90,37 @@ func main() {
 	gin.DefaultErrorWriter = ginzero.NewWriter(zerolog.ErrorLevel)
 	router := gin.New() // not gin.Default()
 	router.Use(ginzero.Logger())
+	collector := metrics.NewCollector(metrics.Options{Namespace: appName})
+	router.Use(collector.Middleware())
 
-	router.GET("/links", func(c *gin.Context) {
-		handler.Links(c, "",
-			handler.LinkDef{"/links", "Links", "custom links page (this one)"},
-			handler.LinkDef{"/ping", "Ping", "server existence"},
-			handler.LinkDef{"/exit", "Exit", "graceful shut down"})
-	})
-	router.GET("/ping", handler.Ping)
-	router.GET("/exit", handler.Exit)
+	routes := handler.NewRouteIndex(router)
+	routes.GET(router, "/links", "route index (this page)", routes.Handler)
+	routes.GET(router, "/ping", "server existence", handler.Ping)
+	health := handler.NewHealth(0) // register dependency checks via health.Register()
+	routes.GET(router, "/health", "dependency health checks", health.Handler)
+	routes.GET(router, "/info", "build and runtime info",
+		handler.Info(handler.InfoOptions{Server: graceful, Config: config.Gin.Info()}))
+	exit := handler.ExitWithOptions(handler.ExitOptions{Token: config.ExitToken})
+	routes.Handle(router, http.MethodPost, "/exit", "graceful shut down", exit)
+	routes.GET(router, "/metrics", "request metrics", collector.Handler)
+	routes.GET(router, "/levels", "current log levels", handler.GetLogLevels)
+	levelsAccess := handler.LoopbackOnly
+	if config.LevelsToken != "" {
+		levelsAccess = handler.NewAuth(nil, handler.NewAPIKeyAuth("", map[string]handler.Principal{
+			config.LevelsToken: {Name: "levels"},
+		})).Middleware()
+	}
+	routes.Handle(router, http.MethodPut, "/levels", "change log levels", levelsAccess, handler.PutLogLevels)
+	handler.RegisterDebugRoutes(router.Group("/debug"), handler.DebugOptions{Enabled: config.Gin.Debug, Index: routes})
+	api := handler.NewOpenAPI(router, handler.OpenAPIInfo{Title: appName, Version: "0.0.1"})
+	routes.GET(router, "/openapi", "API description", api.ViewerHandler)
+	routes.GET(router, "/openapi.json", "OpenAPI document (JSON)", api.JSONHandler)
+	routes.GET(router, "/openapi.yaml", "OpenAPI document (YAML)", api.YAMLHandler)
 
 	log.Logger.Info().Msgf("Application %s starting", appName)
 	log.Logger.Info().Msgf("> http://localhost:%d/links", config.Gin.Port)
 	log.Logger.Info().Msgf("> http://localhost:%d/ping", config.Gin.Port)
-	log.Logger.Info().Msgf("> http://localhost:%d/exit", config.Gin.Port)
+	log.Logger.Info().Msgf("> curl -X POST http://localhost:%d/exit", config.Gin.Port)
 	defer log.Logger.Info().Msgf("Application %s finished", appName)
 
 	if err := graceful.Serve(router, 0); err != nil {
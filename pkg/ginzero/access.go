@@ -0,0 +1,261 @@
+package ginzero
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Predefined access log formats using Apache mod_log_config directives.
+const (
+	// CommonLogFormat is the Apache Common Log Format (CLF).
+	CommonLogFormat = `%h %l %u %t "%r" %>s %b`
+
+	// CombinedLogFormat is the Apache Combined Log Format.
+	CombinedLogFormat = `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"`
+)
+
+// AccessLog writes access log lines in a format compatible with Apache mod_log_config.
+// Add an AccessLog to the LoggerOptions to generate an access log line for each request
+// in addition to the zerolog record.
+//
+// The following format directives are supported:
+//
+//	%%          literal percent sign
+//	%b          response size in bytes, "-" if no bytes were sent
+//	%B          response size in bytes, 0 if no bytes were sent
+//	%D          request duration in microseconds
+//	%h          client IP address
+//	%H          request protocol
+//	%l          remote logname, always "-"
+//	%m          request method
+//	%q          query string prefixed with "?" or empty string
+//	%r          first line of request
+//	%s, %>s     response status code
+//	%t          request start time in [02/Jan/2006:15:04:05 -0700] format
+//	%T          request duration in seconds
+//	%u          remote user from gin.AuthUserKey, "-" if not authenticated
+//	%U          request path without query string
+//	%{Name}i    contents of request header Name
+//	%{Name}o    contents of response header Name
+//
+// All values are escaped Apache style wherever they appear in the format,
+// so that client supplied data (e.g. the path or a header) cannot inject fake log lines.
+type AccessLog struct {
+	writer io.Writer
+	items  []accessItem
+	lock   sync.Mutex
+}
+
+// NewAccessLog returns an AccessLog that writes to the specified io.Writer
+// using the specified format, which may be CommonLogFormat, CombinedLogFormat,
+// or a custom format string using the supported directives.
+func NewAccessLog(writer io.Writer, format string) (*AccessLog, error) {
+	if items, err := parseAccessFormat(format); err != nil {
+		return nil, fmt.Errorf("parse access log format: %w", err)
+	} else {
+		return &AccessLog{writer: writer, items: items}, nil
+	}
+}
+
+// Log writes a single access log line for the request in the specified gin.Context.
+// The request is assumed to have started at the specified time and run for the specified duration.
+func (al *AccessLog) Log(c *gin.Context, start time.Time, duration time.Duration) error {
+	var line bytes.Buffer
+	data := &accessData{ctx: c, start: start, duration: duration}
+	for _, item := range al.items {
+		item(&line, data)
+	}
+	line.WriteByte('\n')
+
+	// Write the entire line at once under lock so that lines are not interleaved.
+	al.lock.Lock()
+	defer al.lock.Unlock()
+	if _, err := al.writer.Write(line.Bytes()); err != nil {
+		return fmt.Errorf("write access log: %w", err)
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// accessData holds request data passed to accessItem functions.
+type accessData struct {
+	ctx      *gin.Context
+	start    time.Time
+	duration time.Duration
+}
+
+// accessItem writes a single piece of an access log line.
+type accessItem func(line *bytes.Buffer, data *accessData)
+
+const accessTimeFormat = "[02/Jan/2006:15:04:05 -0700]"
+
+// parseAccessFormat converts a format string into a list of accessItem functions.
+func parseAccessFormat(format string) ([]accessItem, error) {
+	var items []accessItem
+	literal := func(text string) {
+		if text != "" {
+			items = append(items, func(line *bytes.Buffer, _ *accessData) {
+				line.WriteString(text)
+			})
+		}
+	}
+
+	for len(format) > 0 {
+		pct := strings.IndexByte(format, '%')
+		if pct < 0 {
+			literal(format)
+			break
+		}
+		literal(format[:pct])
+		format = format[pct+1:]
+
+		var name string
+		if strings.HasPrefix(format, "{") {
+			end := strings.IndexByte(format, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %%{ in format")
+			}
+			name = format[1:end]
+			format = format[end+1:]
+		}
+		format = strings.TrimPrefix(format, ">")
+		if format == "" {
+			return nil, fmt.Errorf("format ends with %%")
+		}
+
+		directive := format[0]
+		format = format[1:]
+		value, err := accessValue(directive, name)
+		if err != nil {
+			return nil, err
+		}
+		if directive == '%' {
+			literal("%")
+			continue
+		}
+		items = append(items, func(line *bytes.Buffer, data *accessData) {
+			line.WriteString(escapeAccess(value(data)))
+		})
+	}
+
+	return items, nil
+}
+
+// accessValue returns a function that returns the value for the specified directive.
+func accessValue(directive byte, name string) (func(data *accessData) string, error) {
+	switch directive {
+	case '%':
+		return nil, nil
+	case 'b':
+		return func(data *accessData) string {
+			if size := data.ctx.Writer.Size(); size > 0 {
+				return strconv.Itoa(size)
+			}
+			return "-"
+		}, nil
+	case 'B':
+		return func(data *accessData) string {
+			return strconv.Itoa(max(data.ctx.Writer.Size(), 0))
+		}, nil
+	case 'D':
+		return func(data *accessData) string {
+			return strconv.FormatInt(data.duration.Microseconds(), 10)
+		}, nil
+	case 'h':
+		return func(data *accessData) string {
+			return data.ctx.ClientIP()
+		}, nil
+	case 'H':
+		return func(data *accessData) string {
+			return data.ctx.Request.Proto
+		}, nil
+	case 'l':
+		return func(_ *accessData) string {
+			return "-"
+		}, nil
+	case 'm':
+		return func(data *accessData) string {
+			return data.ctx.Request.Method
+		}, nil
+	case 'q':
+		return func(data *accessData) string {
+			if raw := data.ctx.Request.URL.RawQuery; raw != "" {
+				return "?" + raw
+			}
+			return ""
+		}, nil
+	case 'r':
+		return func(data *accessData) string {
+			return data.ctx.Request.Method + " " + data.ctx.Request.URL.RequestURI() + " " + data.ctx.Request.Proto
+		}, nil
+	case 's':
+		return func(data *accessData) string {
+			return strconv.Itoa(data.ctx.Writer.Status())
+		}, nil
+	case 't':
+		return func(data *accessData) string {
+			return data.start.Format(accessTimeFormat)
+		}, nil
+	case 'T':
+		return func(data *accessData) string {
+			return strconv.FormatInt(int64(data.duration/time.Second), 10)
+		}, nil
+	case 'u':
+		return func(data *accessData) string {
+			return dashIfEmpty(data.ctx.GetString(gin.AuthUserKey))
+		}, nil
+	case 'U':
+		return func(data *accessData) string {
+			return data.ctx.Request.URL.Path
+		}, nil
+	case 'i':
+		if name == "" {
+			return nil, fmt.Errorf("missing header name for %%i")
+		}
+		return func(data *accessData) string {
+			return dashIfEmpty(data.ctx.Request.Header.Get(name))
+		}, nil
+	case 'o':
+		if name == "" {
+			return nil, fmt.Errorf("missing header name for %%o")
+		}
+		return func(data *accessData) string {
+			return dashIfEmpty(data.ctx.Writer.Header().Get(name))
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown directive %%%c", directive)
+	}
+}
+
+// dashIfEmpty returns "-" for an empty value as Apache does.
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// escapeAccess escapes quotes, backslashes, and non-printable characters Apache style.
+func escapeAccess(text string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch ch := text[i]; {
+		case ch == '"' || ch == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(ch)
+		case ch < 0x20 || ch >= 0x7f:
+			_, _ = fmt.Fprintf(&escaped, "\\x%02x", ch)
+		default:
+			escaped.WriteByte(ch)
+		}
+	}
+	return escaped.String()
+}
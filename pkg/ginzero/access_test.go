@@ -0,0 +1,117 @@
+package ginzero
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccessLog_BadFormat(t *testing.T) {
+	for _, format := range []string{"%h %", "%{Referer", "%z", "%i"} {
+		_, err := NewAccessLog(&bytes.Buffer{}, format)
+		assert.Error(t, err, format)
+	}
+}
+
+func TestAccessLog_Common(t *testing.T) {
+	line := accessLine(t, CommonLogFormat)
+	assert.Regexp(t,
+		regexp.MustCompile(`^192\.0\.2\.1 - - \[\d\d/\w\w\w/\d{4}:\d\d:\d\d:\d\d [-+]\d{4}] "GET /test\?goober=snoofus HTTP/1\.1" 201 5\n$`),
+		line)
+}
+
+func TestAccessLog_Combined(t *testing.T) {
+	line := accessLine(t, CombinedLogFormat)
+	assert.Contains(t, line, `"GET /test?goober=snoofus HTTP/1.1" 201 5 "-" "agent \"double-oh\" 7"`)
+}
+
+func TestAccessLog_Custom(t *testing.T) {
+	line := accessLine(t, `%m %U%q %s %B %{X-Result}o 100%%`)
+	assert.Equal(t, "GET /test?goober=snoofus 201 5 done 100%\n", line)
+}
+
+func TestAccessLog_Escape(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	accessLog, err := NewAccessLog(buffer, `%U %u %{X-Name}i "%{X-Name}i"`)
+	require.NoError(t, err)
+	ctxt, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctxt.Request = httptest.NewRequest(http.MethodGet, "/test%0Afake", nil)
+	ctxt.Request.Header.Set("X-Name", `a "b" \c`)
+	ctxt.Set(gin.AuthUserKey, "user\n192.0.2.9")
+	ctxt.Status(http.StatusOK)
+	require.NoError(t, accessLog.Log(ctxt, time.Now(), time.Millisecond))
+	assert.Equal(t, `/test\x0afake user\x0a192.0.2.9 a \"b\" \\c "a \"b\" \\c"`+"\n", buffer.String())
+}
+
+func TestLoggerWithOptions_AccessLog(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	zBuffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(zBuffer)
+
+	buffer := &bytes.Buffer{}
+	accessLog, err := NewAccessLog(buffer, `%m %U %s`)
+	require.NoError(t, err)
+	router := gin.New()
+	router.Use(LoggerWithOptions(LoggerOptions{AccessLog: accessLog}))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+	assert.Equal(t, "GET /test 204\n", buffer.String())
+	assert.Contains(t, zBuffer.String(), `"code":204`)
+}
+
+func TestReopenFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+	rf, err := OpenReopenFile(path)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, rf.Close()) }()
+	stop := rf.ReopenOnSignal()
+	defer stop()
+
+	_, err = rf.Write([]byte("first\n"))
+	require.NoError(t, err)
+	rotated := filepath.Join(dir, "access.log.1")
+	require.NoError(t, os.Rename(path, rotated))
+
+	// Signal the process and wait for the file to be recreated.
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	_, err = rf.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(data))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(data))
+}
+
+func accessLine(t *testing.T, format string) string {
+	buffer := &bytes.Buffer{}
+	accessLog, err := NewAccessLog(buffer, format)
+	require.NoError(t, err)
+	ctxt, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctxt.Request = httptest.NewRequest(http.MethodGet, "/test?goober=snoofus", nil)
+	ctxt.Request.Header.Set("User-Agent", `agent "double-oh" 7`)
+	ctxt.Header("X-Result", "done")
+	ctxt.String(http.StatusCreated, "hello")
+	require.NoError(t, accessLog.Log(ctxt, time.Now(), time.Millisecond))
+	return buffer.String()
+}
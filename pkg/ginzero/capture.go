@@ -0,0 +1,176 @@
+package ginzero
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Capture redirects the process stdout and stderr streams into zerolog.
+//
+// Gin's own messaging can be trapped by replacing gin.DefaultWriter and gin.DefaultErrorWriter,
+// but third-party libraries that use fmt.Println or write to os.Stderr bypass those variables.
+// A Capture object redirects stdout and stderr to pipes and
+// parses each line written to them using the same prefix logic as the ginzero.Writer.
+// Lines on stdout default to zerolog.InfoLevel and lines on stderr to zerolog.ErrorLevel.
+//
+// On Unix systems the process file descriptors 1 and 2 are redirected,
+// so output from C libraries (via cgo), child processes that inherit the descriptors,
+// and loggers that already hold os.Stdout or os.Stderr (including the standard library log package) is captured.
+// Zerolog output must not be written to os.Stdout or os.Stderr while capturing,
+// since it would be captured and logged again endlessly.
+// Write it to the original streams returned by Capture.Stdout() and Capture.Stderr() instead,
+// e.g. by setting the Out field of a zerolog.ConsoleWriter after calling CaptureStd().
+// Output from a fatal error in the Go runtime is written to the pipe as the process exits and is lost.
+//
+// On other systems only the os.Stdout and os.Stderr variables are replaced
+// (and the standard library log package output if it has not been changed from os.Stderr).
+// Loggers that already hold the original os.Stdout or os.Stderr object continue to write to the original stream.
+//
+// Call Restore() on shutdown to return the original streams.
+type Capture struct {
+	stdout *captured
+	stderr *captured
+	// Standard library log output before the capture, nil if not captured.
+	logOutput io.Writer
+}
+
+// CaptureStd starts capturing the process stdout and stderr streams.
+// The original streams are returned by calling Restore() on the result.
+func CaptureStd() (*Capture, error) {
+	stdout, err := capture(1, &os.Stdout, &writer{level: zerolog.InfoLevel, sys: "stdout"})
+	if err != nil {
+		return nil, fmt.Errorf("capture stdout: %w", err)
+	}
+	stderr, err := capture(2, &os.Stderr, &writer{level: zerolog.ErrorLevel, sys: "stderr"})
+	if err != nil {
+		return nil, errors.Join(
+			fmt.Errorf("capture stderr: %w", err),
+			stdout.restore())
+	}
+	c := &Capture{stdout: stdout, stderr: stderr}
+	// If file descriptor 2 was redirected the standard library log output is already captured.
+	if output := stdlog.Writer(); output == stderr.original {
+		c.logOutput = output
+		stdlog.SetOutput(os.Stderr)
+	}
+	return c, nil
+}
+
+// Stdout returns the original stdout stream, which is not captured.
+func (c *Capture) Stdout() *os.File {
+	return c.stdout.original
+}
+
+// Stderr returns the original stderr stream, which is not captured.
+func (c *Capture) Stderr() *os.File {
+	return c.stderr.original
+}
+
+// Restore returns the original stdout and stderr streams.
+// Any lines already written to the captured streams are logged before Restore returns,
+// which waits for any child processes that inherited the captured streams to close them.
+// It is safe to call Restore more than once.
+func (c *Capture) Restore() error {
+	if c.logOutput != nil && stdlog.Writer() == c.stderr.writer {
+		stdlog.SetOutput(c.logOutput)
+	}
+	return errors.Join(c.stdout.restore(), c.stderr.restore())
+}
+
+// RestoreForDefer calls Restore ignoring any error.
+func (c *Capture) RestoreForDefer() {
+	_ = c.Restore()
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// captured represents a single captured stream.
+type captured struct {
+	// Process file descriptor of the stream.
+	fd int
+	// Variable holding the stream.
+	stream **os.File
+	// Original stream, which may be a duplicate of the original file descriptor.
+	original *os.File
+	reader   *os.File
+	writer   *os.File
+	done     chan struct{}
+	once     sync.Once
+}
+
+// capture redirects the specified stream to a pipe
+// and starts a goroutine to log lines read from the pipe.
+func capture(fd int, stream **os.File, w *writer) (*captured, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("create pipe: %w", err)
+	}
+	c := &captured{
+		fd:     fd,
+		stream: stream,
+		reader: reader,
+		writer: writer,
+		done:   make(chan struct{}),
+	}
+	if err := c.redirect(); err != nil {
+		return nil, errors.Join(err, writer.Close(), reader.Close())
+	}
+	go c.process(w)
+	return c, nil
+}
+
+// captureMaxLine is the maximum length of a logged line,
+// longer lines are logged in pieces.
+const captureMaxLine = 64 * 1024
+
+// process logs each line read from the pipe until the pipe is closed.
+// The pipe must always be drained, otherwise writes to the captured stream block.
+func (c *captured) process(w *writer) {
+	defer close(c.done)
+	reader := bufio.NewReaderSize(c.reader, captureMaxLine)
+	for {
+		data, _, err := reader.ReadLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, os.ErrClosed) {
+				log.Error().Err(err).Str("sys", w.sys).Ctx(sysContext(w.sys)).Msg("Reading captured stream")
+			}
+			return
+		}
+		line := string(data)
+		if event, msg, err := w.event(line); err != nil {
+			// Unknown prefix, log the line as is at the default level.
+			if SysEnabled(w.sys, w.level) {
+				log.WithLevel(w.level).Str("sys", w.sys).Ctx(sysContext(w.sys)).Msg(line)
+			}
+		} else {
+			event.Msg(msg)
+		}
+	}
+}
+
+// restore returns the original stream and waits for the logging goroutine to finish.
+func (c *captured) restore() error {
+	var err error
+	c.once.Do(func() {
+		if err = c.unredirect(); err != nil {
+			return
+		}
+		if err = c.writer.Close(); err != nil {
+			err = fmt.Errorf("close pipe writer: %w", err)
+			return
+		}
+		<-c.done
+		if err = c.reader.Close(); err != nil {
+			err = fmt.Errorf("close pipe reader: %w", err)
+		}
+	})
+	return err
+}
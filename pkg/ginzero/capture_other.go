@@ -0,0 +1,17 @@
+//go:build !unix
+
+package ginzero
+
+// redirect replaces the stream variable with the pipe.
+// Process file descriptors can't be redirected on this system.
+func (c *captured) redirect() error {
+	c.original = *c.stream
+	*c.stream = c.writer
+	return nil
+}
+
+// unredirect returns the original stream to the stream variable.
+func (c *captured) unredirect() error {
+	*c.stream = c.original
+	return nil
+}
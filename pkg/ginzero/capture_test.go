@@ -0,0 +1,115 @@
+package ginzero
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	stdlog "log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureStd(t *testing.T) {
+	// Trap output from captured streams.
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	// The stdout and stderr streams are logged from separate goroutines.
+	log.Logger = zerolog.New(zerolog.SyncWriter(buffer))
+
+	// Standard library log prefixes lines with the date and time by default.
+	flags := stdlog.Flags()
+	defer stdlog.SetFlags(flags)
+	stdlog.SetFlags(0)
+
+	stdout, stderr := os.Stdout, os.Stderr
+	capture, err := CaptureStd()
+	require.NoError(t, err)
+	require.NotNil(t, capture)
+	assert.NotEqual(t, stdout, capture.Stdout())
+	assert.NotEqual(t, stderr, capture.Stderr())
+	fmt.Println("TestCaptureStd stdout")
+	_, _ = fmt.Fprintln(os.Stderr, "[WARNING] TestCaptureStd stderr")
+	_, _ = fmt.Fprintln(os.Stderr, "[BAD] TestCaptureStd unknown")
+	stdlog.Print("[INFO] TestCaptureStd log")
+	require.NoError(t, capture.Restore())
+	require.NoError(t, capture.Restore())
+	assert.Equal(t, stdout, os.Stdout)
+	assert.Equal(t, stderr, os.Stderr)
+	assert.Equal(t, stdout, capture.Stdout())
+	assert.Equal(t, stderr, capture.Stderr())
+	assert.Equal(t, stderr, stdlog.Writer())
+
+	// Process log output which is in JSON, one record per line.
+	records := make(map[string]map[string]interface{})
+	scanner := bufio.NewScanner(buffer)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records[record["message"].(string)] = record
+	}
+	require.Len(t, records, 4)
+	record := records["TestCaptureStd stdout"]
+	require.NotNil(t, record)
+	assert.Equal(t, "info", record["level"])
+	assert.Equal(t, "stdout", record["sys"])
+	record = records["TestCaptureStd stderr"]
+	require.NotNil(t, record)
+	assert.Equal(t, "warn", record["level"])
+	assert.Equal(t, "stderr", record["sys"])
+	record = records["[BAD] TestCaptureStd unknown"]
+	require.NotNil(t, record)
+	assert.Equal(t, "error", record["level"])
+	assert.Equal(t, "stderr", record["sys"])
+	record = records["TestCaptureStd log"]
+	require.NotNil(t, record)
+	assert.Equal(t, "info", record["level"])
+	assert.Equal(t, "stderr", record["sys"])
+}
+
+func TestCaptureStd_LongLine(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(zerolog.SyncWriter(buffer))
+
+	capture, err := CaptureStd()
+	require.NoError(t, err)
+	defer capture.RestoreForDefer()
+	written := make(chan error, 1)
+	go func() {
+		_, err := fmt.Println(strings.Repeat("x", captureMaxLine+10))
+		if err == nil {
+			_, err = fmt.Println("TestCaptureStd after")
+		}
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "write blocked after long line")
+	}
+	require.NoError(t, capture.Restore())
+
+	var messages []string
+	scanner := bufio.NewScanner(buffer)
+	scanner.Buffer(nil, 2*captureMaxLine)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		messages = append(messages, record["message"].(string))
+	}
+	require.Len(t, messages, 3)
+	assert.Len(t, messages[0], captureMaxLine)
+	assert.Equal(t, "xxxxxxxxxx", messages[1])
+	assert.Equal(t, "TestCaptureStd after", messages[2])
+}
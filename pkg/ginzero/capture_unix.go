@@ -0,0 +1,37 @@
+//go:build unix
+
+package ginzero
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// redirect points the process file descriptor at the pipe,
+// keeping a duplicate of the original file descriptor as the original stream.
+func (c *captured) redirect() error {
+	saved, err := unix.Dup(c.fd)
+	if err != nil {
+		return fmt.Errorf("duplicate file descriptor %d: %w", c.fd, err)
+	}
+	if err := unix.Dup2(int(c.writer.Fd()), c.fd); err != nil {
+		_ = unix.Close(saved)
+		return fmt.Errorf("redirect file descriptor %d: %w", c.fd, err)
+	}
+	c.original = os.NewFile(uintptr(saved), (*c.stream).Name())
+	return nil
+}
+
+// unredirect points the process file descriptor back at the original stream.
+func (c *captured) unredirect() error {
+	if err := unix.Dup2(int(c.original.Fd()), c.fd); err != nil {
+		return fmt.Errorf("restore file descriptor %d: %w", c.fd, err)
+	}
+	if err := c.original.Close(); err != nil {
+		return fmt.Errorf("close duplicate file descriptor: %w", err)
+	}
+	c.original = *c.stream
+	return nil
+}
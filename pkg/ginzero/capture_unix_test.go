@@ -0,0 +1,39 @@
+//go:build unix
+
+package ginzero
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestCaptureStd_FileDescriptors(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(zerolog.SyncWriter(buffer))
+
+	// A logger configured before the capture holds the stream object.
+	held := os.Stderr
+	capture, err := CaptureStd()
+	require.NoError(t, err)
+	_, err = unix.Write(1, []byte("TestCaptureStd descriptor\n"))
+	require.NoError(t, err)
+	_, err = fmt.Fprintln(held, "TestCaptureStd held")
+	require.NoError(t, err)
+	_, err = fmt.Fprintln(capture.Stderr(), "TestCaptureStd original (not captured)")
+	require.NoError(t, err)
+	require.NoError(t, capture.Restore())
+
+	assert.Contains(t, buffer.String(), `"sys":"stdout","message":"TestCaptureStd descriptor"`)
+	assert.Contains(t, buffer.String(), `"sys":"stderr","message":"TestCaptureStd held"`)
+	assert.NotContains(t, buffer.String(), "TestCaptureStd original")
+}
@@ -1,6 +1,7 @@
 // Package ginzero provides tools for using [zerolog] from within [gin] applications.
 // This includes the ginzero.Logger [gin] Middleware to dump request data into [zerolog] and
 // the ginzero.Writer IO.Writer to trap low-level [gin] messaging.
+// The ginzero.CaptureStd function can also capture the process stdout and stderr streams.
 //
 // [gin]: https://github.com/gin-gonic/gin
 // [zerolog]: https://github.com/rs/zerolog
@@ -36,6 +37,82 @@
 // The latter adds its own logging middleware
 // which would conflict with the ginzero middleware.
 //
+// # Request Errors
+//
+// Errors attached to the gin.Context (via gin.Context.Error) are logged as
+// an array of structured elements containing the error text, type, and meta data.
+// The log level is raised to zerolog.ErrorLevel for private and render errors
+// and to zerolog.WarnLevel for public and bind errors.
+//
+// # Tracing
+//
+// If the gin.Context has string values for TraceIDKey and SpanIDKey
+// (set by the tracing package middleware) they are added to the request record
+// as the trace_id and span_id fields.
+//
+// # Slow Requests
+//
+// Successful requests are normally logged at zerolog.DebugLevel.
+// Requests that exceed a slow request threshold (global or per-route)
+// are escalated to zerolog.WarnLevel with the "slow" flag set.
+// An in-flight watchdog can also warn about requests that are still running:
+//
+//  router.Use(ginzero.LoggerWithOptions(ginzero.LoggerOptions{
+//      SlowThreshold:     time.Second,
+//      SlowRoutes:        map[string]time.Duration{"/export/:id": time.Minute},
+//      InFlightThreshold: 30 * time.Second,
+//  }))
+//
+// # Log Levels
+//
+// The global zerolog level and per-subsystem levels (by "sys" field value)
+// can be changed at runtime using SetLevels, optionally reverting after a TTL.
+// Subsystem levels are applied by ginzero.Writer and by loggers from SysLogger.
+// After HookLevels is called a subsystem level may be lower than the global level.
+// The handler package provides GetLogLevels and PutLogLevels to do this via HTTP.
+//
+// # Access Log
+//
+// An Apache style access log can be written in addition to the zerolog records:
+//
+//  file, err := ginzero.OpenReopenFile("/var/log/app/access.log")
+//  if err != nil {
+//      log.Fatal().Err(err).Msg("Open access log")
+//  }
+//  defer func() { _ = file.Close() }()
+//  stop := file.ReopenOnSignal() // reopen on SIGHUP after log rotation
+//  defer stop()
+//  accessLog, err := ginzero.NewAccessLog(file, ginzero.CombinedLogFormat)
+//  if err != nil {
+//      log.Fatal().Err(err).Msg("Create access log")
+//  }
+//  router.Use(ginzero.LoggerWithOptions(ginzero.LoggerOptions{AccessLog: accessLog}))
+//
+// Use ginzero.LoggerWithOptions instead of ginzero.Logger in this case.
+// Custom formats can be specified using Apache mod_log_config directives.
+//
+// # Capture Process Streams
+//
+// Third-party libraries may write directly to os.Stdout or os.Stderr,
+// bypassing the gin writer variables.
+// Capture these streams (after configuring zerolog output) using the following:
+//
+//  capture, err := ginzero.CaptureStd()
+//  if err != nil {
+//      log.Fatal().Err(err).Msg("Capture process streams")
+//  }
+//  defer capture.RestoreForDefer()
+//
+// Each captured line is parsed the same way as for ginzero.Writer.
+// On Unix systems the process file descriptors are redirected,
+// so zerolog console output must then be sent to the original streams:
+//
+//  if writer := config.Log.Writer(); writer != nil && writer.Out == os.Stderr {
+//      writer.Out = capture.Stderr()
+//  }
+//
+// # Run Server
+//
 // Add routing configuration after these statements.
 // Actual router configuration will depend on the application.
 // After configuration run the server.
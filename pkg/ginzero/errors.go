@@ -0,0 +1,82 @@
+package ginzero
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// errorLevel returns the zerolog.Level appropriate for the specified gin.Error.
+// Private and render errors represent server failures and are logged at zerolog.ErrorLevel.
+// Public and bind errors are the result of client requests and are logged at zerolog.WarnLevel.
+func errorLevel(err *gin.Error) zerolog.Level {
+	if err.IsType(gin.ErrorTypePrivate) || err.IsType(gin.ErrorTypeRender) {
+		return zerolog.ErrorLevel
+	}
+	return zerolog.WarnLevel
+}
+
+// errorTypeNames maps gin.ErrorType flags to names for logging.
+var errorTypeNames = []struct {
+	flag gin.ErrorType
+	name string
+}{
+	{gin.ErrorTypeBind, "bind"},
+	{gin.ErrorTypeRender, "render"},
+	{gin.ErrorTypePrivate, "private"},
+	{gin.ErrorTypePublic, "public"},
+}
+
+// errorTypeName returns a name for the specified gin.ErrorType flags.
+// Multiple flags are separated by "|", unknown flags are shown in hex.
+func errorTypeName(errorType gin.ErrorType) string {
+	var names []string
+	for _, etn := range errorTypeNames {
+		if errorType&etn.flag != 0 {
+			names = append(names, etn.name)
+			errorType &^= etn.flag
+		}
+	}
+	if errorType != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint64(errorType)))
+	}
+	return strings.Join(names, "|")
+}
+
+// errorsMessage returns a short human message for the specified errors.
+func errorsMessage(errs []*gin.Error) string {
+	switch len(errs) {
+	case 0:
+		return "Request"
+	case 1:
+		return errs[0].Error()
+	default:
+		return fmt.Sprintf("%s (+%d more)", errs[0].Error(), len(errs)-1)
+	}
+}
+
+// errorsArray returns a zerolog array containing a structured element for each error.
+func errorsArray(errs []*gin.Error) *zerolog.Array {
+	array := zerolog.Arr()
+	for _, err := range errs {
+		array = array.Object((*logError)(err))
+	}
+	return array
+}
+
+// Make sure the logError struct implements zerolog.LogObjectMarshaler.
+var _ = zerolog.LogObjectMarshaler(&logError{})
+
+// logError wraps gin.Error to provide zerolog object marshaling.
+type logError gin.Error
+
+// MarshalZerologObject adds the error text, type, and meta data (if any) to the event.
+func (le *logError) MarshalZerologObject(event *zerolog.Event) {
+	event.Str("error", (*gin.Error)(le).Error())
+	event.Str("type", errorTypeName(le.Type))
+	if le.Meta != nil {
+		event.Interface("meta", le.Meta)
+	}
+}
@@ -0,0 +1,215 @@
+package ginzero
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Levels represents the global zerolog level and any subsystem level overrides.
+// Subsystems are identified by the "sys" field (e.g. "gin" or "graceful").
+//
+// A subsystem level lower than the global level only takes effect after HookLevels is called,
+// otherwise the zerolog global level is applied first and
+// a subsystem level can only restrict logging further than the global level.
+type Levels struct {
+	Global zerolog.Level
+	Sys    map[string]zerolog.Level
+}
+
+var levels = struct {
+	// HookLevels has been called.
+	hooked bool
+	sys    map[string]zerolog.Level
+	revert *Levels
+	timer  *time.Timer
+	when   time.Time
+	// Incremented by each SetLevels so that stale reversion timers are ignored.
+	generation uint64
+	lock       sync.RWMutex
+}{
+	sys: make(map[string]zerolog.Level),
+}
+
+// filter is a copy of the levels for SysEnabled,
+// which is called by hooks and can't take the levels lock as events are logged while it is held.
+var filter atomic.Pointer[levelFilter]
+
+type levelFilter struct {
+	// Global level requested by SetLevels, only used when lowered is true.
+	global zerolog.Level
+	// The zerolog global level has been lowered below the global level for a subsystem.
+	lowered bool
+	sys     map[string]zerolog.Level
+}
+
+// CurrentLevels returns the current global and subsystem levels.
+// If the levels are set to revert after a TTL the time of reversion is also returned,
+// otherwise the returned time.Time will be zero.
+func CurrentLevels() (Levels, time.Time) {
+	levels.lock.RLock()
+	defer levels.lock.RUnlock()
+	return Levels{
+		Global: globalLevel(),
+		Sys:    maps.Clone(levels.sys),
+	}, levels.when
+}
+
+// SetLevels changes the global and subsystem levels.
+// A Global value of zerolog.NoLevel leaves the global level unchanged.
+// A Sys value of zerolog.NoLevel removes the override for that subsystem.
+//
+// If the ttl is non-zero the levels will revert to their previous values after that duration.
+// Setting levels again before the reversion cancels any pending reversion,
+// but a new ttl will revert to the levels that were in effect before the first change.
+func SetLevels(changes Levels, ttl time.Duration) {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+
+	if levels.timer != nil {
+		levels.timer.Stop()
+		levels.timer = nil
+		levels.when = time.Time{}
+	}
+	// A timer that has already fired may be waiting for the lock,
+	// it is ignored because its generation is out of date.
+	levels.generation++
+	revert := levels.revert
+	levels.revert = nil
+	if ttl > 0 {
+		if revert == nil {
+			revert = &Levels{Global: globalLevel(), Sys: maps.Clone(levels.sys)}
+		}
+		levels.revert = revert
+		levels.when = time.Now().Add(ttl)
+		generation := levels.generation
+		levels.timer = time.AfterFunc(ttl, func() { revertLevels(generation) })
+	}
+
+	global := globalLevel()
+	if changes.Global != zerolog.NoLevel {
+		global = changes.Global
+	}
+	for sys, level := range changes.Sys {
+		if level == zerolog.NoLevel {
+			delete(levels.sys, sys)
+		} else {
+			levels.sys[sys] = level
+		}
+	}
+	applyLevels(global)
+}
+
+// revertLevels returns the levels to the values saved by SetLevels
+// unless the levels have been set again since the reversion was scheduled.
+func revertLevels(generation uint64) {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+	if levels.revert != nil && generation == levels.generation {
+		levels.sys = levels.revert.Sys
+		applyLevels(levels.revert.Global)
+		levels.revert = nil
+		levels.timer = nil
+		levels.when = time.Time{}
+		log.Info().Str("global", globalLevel().String()).Msg("Log levels reverted")
+	}
+}
+
+// globalLevel returns the requested global level,
+// which may be higher than the zerolog global level.
+// The levels lock must be held.
+func globalLevel() zerolog.Level {
+	if current := filter.Load(); current != nil && current.lowered {
+		return current.global
+	}
+	return zerolog.GlobalLevel()
+}
+
+// applyLevels sets the requested global level.
+// If HookLevels has been called the zerolog global level is set to the lowest of
+// the global and subsystem levels so that events for subsystems with lower levels are created.
+// The levels lock must be held.
+func applyLevels(global zerolog.Level) {
+	lowest := global
+	if levels.hooked {
+		for _, level := range levels.sys {
+			if level < lowest {
+				lowest = level
+			}
+		}
+	}
+	filter.Store(&levelFilter{global: global, lowered: lowest != global, sys: maps.Clone(levels.sys)})
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// SysEnabled returns true if an event at the specified level
+// should be logged for the specified subsystem.
+// Subsystems without a level of their own use the global level.
+func SysEnabled(sys string, level zerolog.Level) bool {
+	if current := filter.Load(); current != nil {
+		if sysLevel, found := current.sys[sys]; found {
+			return level >= sysLevel
+		}
+		if current.lowered {
+			return level >= current.global
+		}
+	}
+	return level >= zerolog.GlobalLevel()
+}
+
+// sysKey is the context key for the subsystem of an event.
+type sysKey struct{}
+
+// sysContext returns a context marking events for the specified subsystem.
+// The hook added by HookLevels uses it to find the subsystem of an event,
+// since hooks can't read event fields.
+func sysContext(sys string) context.Context {
+	return context.WithValue(context.Background(), sysKey{}, sys)
+}
+
+// HookLevels adds a hook to log.Logger that applies the levels set by SetLevels.
+// Events from a subsystem (created by SysLogger or a ginzero.Writer) are filtered by the subsystem level
+// and all other events by the global level.
+// This allows a subsystem level lower than the global level,
+// as the zerolog global level is then set to the lowest of the global and subsystem levels.
+//
+// Call HookLevels after configuring log.Logger and before logging concurrently,
+// since log.Logger is replaced:
+//
+//	config.Log.Setup()
+//	ginzero.HookLevels()
+func HookLevels() {
+	levels.lock.Lock()
+	defer levels.lock.Unlock()
+	log.Logger = log.Logger.Hook(zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, _ string) {
+		sys, _ := e.GetCtx().Value(sysKey{}).(string)
+		if !SysEnabled(sys, level) {
+			e.Discard()
+		}
+	}))
+	global := globalLevel()
+	levels.hooked = true
+	applyLevels(global)
+}
+
+// SysLogger returns a logger derived from log.Logger for the specified subsystem.
+// Events have the "sys" field and are filtered by the subsystem level if one is set.
+// Setting a context on an event with Event.Ctx() hides the subsystem from the hook added by HookLevels.
+func SysLogger(sys string) zerolog.Logger {
+	return log.Logger.With().Str("sys", sys).Ctx(sysContext(sys)).Logger().Hook(SysHook(sys))
+}
+
+// SysHook returns a zerolog.Hook that discards events below the level for the specified subsystem.
+// It is added to loggers returned by SysLogger.
+func SysHook(sys string) zerolog.Hook {
+	return zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, _ string) {
+		if !SysEnabled(sys, level) {
+			e.Discard()
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package ginzero
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLevels(t *testing.T) {
+	global := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(global)
+
+	SetLevels(Levels{Global: zerolog.InfoLevel, Sys: map[string]zerolog.Level{"gin": zerolog.WarnLevel}}, 0)
+	current, when := CurrentLevels()
+	assert.Equal(t, zerolog.InfoLevel, current.Global)
+	assert.Equal(t, map[string]zerolog.Level{"gin": zerolog.WarnLevel}, current.Sys)
+	assert.True(t, when.IsZero())
+	assert.False(t, SysEnabled("gin", zerolog.InfoLevel))
+	assert.True(t, SysEnabled("gin", zerolog.ErrorLevel))
+	assert.True(t, SysEnabled("graceful", zerolog.InfoLevel))
+
+	// Change with TTL, then change again before reversion.
+	SetLevels(Levels{Global: zerolog.DebugLevel}, time.Hour)
+	SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"gin": zerolog.NoLevel}}, 20*time.Millisecond)
+	current, when = CurrentLevels()
+	assert.Equal(t, zerolog.DebugLevel, current.Global)
+	assert.Empty(t, current.Sys)
+	assert.False(t, when.IsZero())
+
+	// Levels revert to those before the first change with a TTL.
+	require.Eventually(t, func() bool {
+		_, when := CurrentLevels()
+		return when.IsZero()
+	}, time.Second, 5*time.Millisecond)
+	current, _ = CurrentLevels()
+	assert.Equal(t, zerolog.InfoLevel, current.Global)
+	assert.Equal(t, map[string]zerolog.Level{"gin": zerolog.WarnLevel}, current.Sys)
+
+	SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"gin": zerolog.NoLevel}}, 0)
+}
+
+func TestSetLevels_StaleRevert(t *testing.T) {
+	global := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(global)
+	SetLevels(Levels{Global: zerolog.InfoLevel}, 0)
+
+	// The first reversion may fire while the second change is being made.
+	SetLevels(Levels{Global: zerolog.DebugLevel}, time.Millisecond)
+	levels.lock.RLock()
+	stale := levels.generation
+	levels.lock.RUnlock()
+	time.Sleep(time.Millisecond)
+	SetLevels(Levels{Global: zerolog.TraceLevel}, time.Hour)
+	time.Sleep(20 * time.Millisecond)
+	current, when := CurrentLevels()
+	assert.Equal(t, zerolog.TraceLevel, current.Global)
+	assert.False(t, when.IsZero())
+
+	// A timer that fired before the second change but got the lock afterwards is ignored.
+	revertLevels(stale)
+	current, when = CurrentLevels()
+	assert.Equal(t, zerolog.TraceLevel, current.Global)
+	assert.False(t, when.IsZero())
+
+	SetLevels(Levels{Global: zerolog.NoLevel}, 0)
+	current, when = CurrentLevels()
+	assert.Equal(t, zerolog.TraceLevel, current.Global)
+	assert.True(t, when.IsZero())
+}
+
+func TestSysHook(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(buffer)
+	SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"mine": zerolog.WarnLevel}}, 0)
+	defer SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"mine": zerolog.NoLevel}}, 0)
+
+	logger := log.Logger.With().Str("sys", "mine").Logger().Hook(SysHook("mine"))
+	logger.Info().Msg("discarded")
+	assert.Empty(t, buffer.String())
+	logger.Warn().Msg("logged")
+	assert.Contains(t, buffer.String(), "logged")
+
+	// Writer applies subsystem levels based on the "sys" field.
+	buffer.Reset()
+	SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"gin": zerolog.ErrorLevel}}, 0)
+	defer SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"gin": zerolog.NoLevel}}, 0)
+	_, err := NewWriter(zerolog.InfoLevel).Write([]byte("[GIN] discarded"))
+	require.NoError(t, err)
+	assert.Empty(t, buffer.String())
+}
+
+func TestHookLevels(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	global := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(global)
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(buffer)
+	HookLevels()
+	defer func() {
+		SetLevels(Levels{Global: zerolog.NoLevel, Sys: map[string]zerolog.Level{"gin": zerolog.NoLevel}}, 0)
+		levels.lock.Lock()
+		levels.hooked = false
+		levels.lock.Unlock()
+	}()
+
+	// A subsystem level may be lower than the global level.
+	SetLevels(Levels{Global: zerolog.WarnLevel, Sys: map[string]zerolog.Level{"gin": zerolog.DebugLevel}}, time.Hour)
+	current, _ := CurrentLevels()
+	assert.Equal(t, zerolog.WarnLevel, current.Global)
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	assert.True(t, SysEnabled("gin", zerolog.DebugLevel))
+	assert.False(t, SysEnabled("graceful", zerolog.InfoLevel))
+
+	graceful, gin := SysLogger("graceful"), SysLogger("gin")
+	log.Info().Msg("global discarded")
+	graceful.Info().Msg("graceful discarded")
+	assert.Empty(t, buffer.String())
+	log.Warn().Msg("global logged")
+	gin.Debug().Msg("gin logged")
+	_, err := NewWriter(zerolog.InfoLevel).Write([]byte("[GIN-debug] writer logged"))
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), "global logged")
+	assert.Contains(t, buffer.String(), "gin logged")
+	assert.Contains(t, buffer.String(), "writer logged")
+
+	// Reverting logs through the hook while holding the levels lock.
+	levels.lock.RLock()
+	generation := levels.generation
+	levels.lock.RUnlock()
+	revertLevels(generation)
+	current, _ = CurrentLevels()
+	assert.Equal(t, global, current.Global)
+	assert.Equal(t, global, zerolog.GlobalLevel())
+	assert.Contains(t, buffer.String(), "Log levels reverted")
+}
@@ -8,24 +8,81 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// Keys for gin.Context string values that are added to the request record if present.
+// These are set by the tracing middleware.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// LoggerOptions that can be specified to LoggerWithOptions.
+type LoggerOptions struct {
+	// Optional access log to be written for each request
+	// in addition to the zerolog record.
+	AccessLog *AccessLog
+
+	// Requests that take longer than this threshold are logged at
+	// zerolog.WarnLevel (unless the status code requires a higher level)
+	// with the "slow" flag set. A zero value disables slow request detection.
+	SlowThreshold time.Duration
+
+	// Slow request thresholds for specific routes, keyed by route template
+	// (the value of gin.Context.FullPath()), overriding SlowThreshold.
+	// A zero value disables slow request detection for the route.
+	SlowRoutes map[string]time.Duration
+
+	// Requests still running after this threshold generate a warning record
+	// before they complete. A zero value disables the in-flight watchdog.
+	InFlightThreshold time.Duration
+}
+
+// slowThreshold returns the slow request threshold for the specified route.
+func (lo *LoggerOptions) slowThreshold(route string) time.Duration {
+	if threshold, found := lo.SlowRoutes[route]; found {
+		return threshold
+	}
+	return lo.SlowThreshold
+}
+
 // Logger returns a Gin middleware function that generates a zerolog record for the current request.
 // The record will be generated in the format for which zerolog has been configured.
 func Logger() gin.HandlerFunc {
+	return LoggerWithOptions(LoggerOptions{})
+}
+
+// LoggerWithOptions returns a Gin middleware function that generates a zerolog record
+// for the current request as does Logger, configured by the specified options.
+func LoggerWithOptions(options LoggerOptions) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		if options.InFlightThreshold > 0 {
+			watchdog := inFlightWatchdog(c, start, options.InFlightThreshold)
+			defer watchdog.Stop()
+		}
 		c.Next()
 		duration := time.Since(start)
 
-		var event *zerolog.Event
 		code := c.Writer.Status()
+		threshold := options.slowThreshold(c.FullPath())
+		slow := threshold > 0 && duration > threshold
+		level := zerolog.DebugLevel
 		if code >= 400 && code < 500 {
-			event = log.Warn()
+			level = zerolog.WarnLevel
 		} else if code >= 500 {
-			event = log.Error()
-		} else {
-			event = log.Debug()
+			level = zerolog.ErrorLevel
+		} else if slow {
+			level = zerolog.WarnLevel
+		}
+		for _, err := range c.Errors {
+			if errLevel := errorLevel(err); errLevel > level {
+				level = errLevel
+			}
 		}
+		event := log.WithLevel(level)
 		event = event.Int("code", code)
+		if slow {
+			event = event.Bool("slow", true)
+		}
 
 		event = event.Time("time", start)
 		event = event.Dur("dur", duration)
@@ -38,11 +95,43 @@ func Logger() gin.HandlerFunc {
 			path = path + "?" + raw
 		}
 		event = event.Str("path", path)
+		if traceID := c.GetString(TraceIDKey); traceID != "" {
+			event = event.Str("trace_id", traceID)
+		}
+		if spanID := c.GetString(SpanIDKey); spanID != "" {
+			event = event.Str("span_id", spanID)
+		}
+
+		if len(c.Errors) > 0 {
+			event = event.Array("errors", errorsArray(c.Errors))
+		}
+		event.Msg(errorsMessage(c.Errors))
 
-		msg := c.Errors.String()
-		if msg == "" {
-			msg = "Request"
+		if options.AccessLog != nil {
+			if err := options.AccessLog.Log(c, start, duration); err != nil {
+				log.Error().Err(err).Msg("Access log")
+			}
 		}
-		event.Msg(msg)
 	}
 }
+
+// inFlightWatchdog returns a timer that logs a warning if the request
+// is still running after the specified threshold.
+// Request data is collected up front as the gin.Context is in use by the request.
+func inFlightWatchdog(c *gin.Context, start time.Time, threshold time.Duration) *time.Timer {
+	meth := c.Request.Method
+	path := c.Request.URL.Path
+	route := c.FullPath()
+	ip := c.ClientIP()
+	return time.AfterFunc(threshold, func() {
+		log.Warn().
+			Bool("inflight", true).
+			Time("time", start).
+			Dur("dur", time.Since(start)).
+			Str("ip", ip).
+			Str("meth", meth).
+			Str("path", path).
+			Str("route", route).
+			Msg("Request still running")
+	})
+}
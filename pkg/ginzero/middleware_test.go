@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -77,6 +78,108 @@ func testLoggerWithCode(t *testing.T, testCode int, testLvl string) {
 	fmt.Println(buffer.String())
 }
 
+func TestLoggerWithOptions_Slow(t *testing.T) {
+	options := LoggerOptions{
+		SlowThreshold: 10 * time.Millisecond,
+		SlowRoutes: map[string]time.Duration{
+			"/fast/:id": 0,
+			"/slower":   time.Hour,
+		},
+	}
+	record := testLoggerRoute(t, options, "/slow", "/slow", 20*time.Millisecond)
+	assert.Equal(t, "warn", record["level"])
+	assert.Equal(t, true, record["slow"])
+	record = testLoggerRoute(t, options, "/fast/:id", "/fast/1", 20*time.Millisecond)
+	assert.Equal(t, "debug", record["level"])
+	assert.Nil(t, record["slow"])
+	record = testLoggerRoute(t, options, "/slower", "/slower", 20*time.Millisecond)
+	assert.Equal(t, "debug", record["level"])
+	assert.Nil(t, record["slow"])
+}
+
+func TestLoggerWithOptions_InFlight(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	// The watchdog logs from its own goroutine.
+	log.Logger = zerolog.New(zerolog.SyncWriter(buffer))
+	router := gin.New()
+	router.Use(LoggerWithOptions(LoggerOptions{InFlightThreshold: 10 * time.Millisecond}))
+	router.GET("/hang/:id", func(c *gin.Context) {
+		time.Sleep(50 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hang/1", nil))
+
+	// Two records, the first from the watchdog.
+	decoder := json.NewDecoder(buffer)
+	var record map[string]interface{}
+	require.NoError(t, decoder.Decode(&record))
+	assert.Equal(t, "warn", record["level"])
+	assert.Equal(t, true, record["inflight"])
+	assert.Equal(t, "/hang/:id", record["route"])
+	assert.Equal(t, "/hang/1", record["path"])
+	record = nil
+	require.NoError(t, decoder.Decode(&record))
+	assert.Equal(t, "debug", record["level"])
+	assert.Nil(t, record["inflight"])
+}
+
+func TestLoggerWithOptions_Errors(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(buffer)
+	router := gin.New()
+	router.Use(Logger())
+	router.GET("/public", func(c *gin.Context) {
+		_ = c.Error(errors.New("first")).SetType(gin.ErrorTypePublic).SetMeta(gin.H{"field": "name"})
+		_ = c.Error(errors.New("second")).SetType(gin.ErrorTypeBind)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/private", func(c *gin.Context) {
+		_ = c.Error(errors.New("internal"))
+		c.Status(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/public", nil))
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, "warn", record["level"])
+	assert.Equal(t, "first (+1 more)", record["message"])
+	errs, ok := record["errors"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, errs, 2)
+	assert.Equal(t, map[string]interface{}{
+		"error": "first", "type": "public", "meta": map[string]interface{}{"field": "name"},
+	}, errs[0])
+	assert.Equal(t, map[string]interface{}{"error": "second", "type": "bind"}, errs[1])
+
+	buffer.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/private", nil))
+	record = nil
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, "error", record["level"])
+	assert.Equal(t, "internal", record["message"])
+}
+
+func testLoggerRoute(t *testing.T, options LoggerOptions, route, path string, sleep time.Duration) map[string]interface{} {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(buffer)
+	router := gin.New()
+	router.Use(LoggerWithOptions(options))
+	router.GET(route, func(c *gin.Context) {
+		time.Sleep(sleep)
+		c.Status(http.StatusOK)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	return record
+}
+
 //////////////////////////////////////////////////////////////////////////
 
 func ExampleLogger() {
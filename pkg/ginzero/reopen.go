@@ -0,0 +1,100 @@
+package ginzero
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReopenFile is an io.Writer for a log file that can be closed and reopened
+// so that external log rotation tools (e.g. logrotate) can move the file.
+// Use ReopenOnSignal to reopen the file when the process receives SIGHUP.
+type ReopenFile struct {
+	path string
+	file *os.File
+	lock sync.Mutex
+}
+
+// OpenReopenFile opens the specified file path for appending,
+// creating the file if necessary.
+func OpenReopenFile(path string) (*ReopenFile, error) {
+	rf := &ReopenFile{path: path}
+	if err := rf.Reopen(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Write a block of data to the current file.
+func (rf *ReopenFile) Write(p []byte) (n int, err error) {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+	return rf.file.Write(p)
+}
+
+// Reopen closes the current file (if any) and opens the file path again.
+func (rf *ReopenFile) Reopen() error {
+	file, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", rf.path, err)
+	}
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.file != nil {
+		if err = rf.file.Close(); err != nil {
+			log.Warn().Err(err).Str("path", rf.path).Msg("Close file for reopen")
+		}
+	}
+	rf.file = file
+	return nil
+}
+
+// ReopenOnSignal starts a goroutine that reopens the file whenever the process
+// receives one of the specified signals, or SIGHUP if no signals are specified.
+// Call the returned function to stop listening for the signals.
+func (rf *ReopenFile) ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, signals...)
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				if err := rf.Reopen(); err != nil {
+					log.Error().Err(err).Str("path", rf.path).Msg("Reopen file on signal")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigChan)
+			close(done)
+		})
+	}
+}
+
+// Close the current file.
+func (rf *ReopenFile) Close() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
@@ -33,6 +33,9 @@ type writer struct {
 	// Can be overridden by error levels (specified in logLevels variable)
 	// in square brackets at the beginning of a log record line.
 	level zerolog.Level
+	// Default sys field value for this object, may be empty.
+	// Overridden by gin prefixes at the beginning of a log record line.
+	sys string
 }
 
 var (
@@ -51,9 +54,21 @@ var (
 // For the moment we're assuming that there is a single Write() call for each log record.
 // TODO: Fix code to handle multiple Write() calls per log record.
 func (w *writer) Write(p []byte) (n int, err error) {
+	if event, msg, err := w.event(string(p)); err != nil {
+		return 0, err
+	} else {
+		event.Msg(msg)
+		return len(p), nil
+	}
+}
+
+// event parses a single log record, pulling off any prefix sequences that
+// represent log information, and returns a zerolog.Event of the appropriate level
+// along with the remaining message text.
+func (w *writer) event(record string) (*zerolog.Event, string, error) {
 	level := w.level
-	msg := strings.TrimRight(string(p), "\n")
-	var sys string
+	msg := strings.TrimRight(record, "\n")
+	sys := w.sys
 
 	for x := 0; x < 10; x++ { // Don't use infinite for loop for safety
 		// Pull off prefix sequences that represent log information.
@@ -67,7 +82,7 @@ func (w *writer) Write(p []byte) (n int, err error) {
 		} else if matches := ptn_log_level.FindStringSubmatch(msg); len(matches) > 1 {
 			var ok bool
 			if level, ok = logLevels[matches[1]]; !ok {
-				return 0, fmt.Errorf("no level %s", matches[1])
+				return nil, "", fmt.Errorf("no level %s", matches[1])
 			}
 			msg = msg[len(matches[0]):]
 		} else {
@@ -87,13 +102,16 @@ func (w *writer) Write(p []byte) (n int, err error) {
 	case zerolog.WarnLevel:
 		event = log.Warn()
 	default:
-		return 0, fmt.Errorf("unknown log level %s", w.level)
+		return nil, "", fmt.Errorf("unknown log level %s", w.level)
 	}
 
 	if sys != "" {
-		event = event.Str("sys", sys)
+		if !SysEnabled(sys, level) {
+			// Discard returns nil which is safe to use as an Event.
+			return event.Discard(), msg, nil
+		}
+		event = event.Str("sys", sys).Ctx(sysContext(sys))
 	}
-	event.Msg(msg)
 
-	return len(p), nil
+	return event, msg, nil
 }
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Authentication headers and values.
+const (
+	hdrAuthorization    = "Authorization"
+	hdrWWWAuthenticate  = "WWW-Authenticate"
+	hdrAPIKeyDefault    = "X-API-Key"
+	authSchemeAPIKey    = "APIKey"
+	authSchemeBasic     = "Basic"
+	authSchemeBearer    = "Bearer"
+	unauthenticatedText = "Authentication required"
+	unauthorizedText    = "Not authorized"
+)
+
+// PrincipalKey is the gin.Context key for the authenticated Principal.
+const PrincipalKey = "principal"
+
+var (
+	// ErrNoCredentials is returned by an Authenticator if the request has no credentials.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned by an Authenticator if the request credentials are invalid.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrForbidden is added to the gin.Context errors when an authenticated principal is not authorized.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal is an authenticated client.
+type Principal struct {
+	// Name of the principal (user name, API key name, or JWT subject).
+	Name string
+	// Authentication scheme (APIKey, Basic, or Bearer).
+	Scheme string
+	// Roles of the principal (JWT roles or scope claims).
+	Roles []string
+	// Claims from a JWT, nil for other schemes.
+	Claims map[string]any
+}
+
+// HasRole returns true if the principal has the specified role.
+func (p *Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// GetPrincipal returns the authenticated Principal stored on the gin.Context, if any.
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	if value, found := ctx.Get(PrincipalKey); found {
+		principal, ok := value.(*Principal)
+		return principal, ok
+	}
+	return nil, false
+}
+
+// Authenticator authenticates requests for a single scheme.
+type Authenticator interface {
+	// Authenticate returns the principal for the request credentials.
+	// Returns ErrNoCredentials if there are no credentials for the scheme
+	// and an error wrapping ErrInvalidCredentials if they are not valid.
+	Authenticate(request *http.Request) (*Principal, error)
+
+	// Challenge returns the WWW-Authenticate header value for the specified error,
+	// which is nil for authorization failures.
+	Challenge(err error) string
+}
+
+// AuthorizeFunc returns true if the principal may access the requested resource.
+type AuthorizeFunc func(ctx *gin.Context, principal *Principal) bool
+
+// RequireRoles returns an AuthorizeFunc that requires the principal to have all the specified roles.
+func RequireRoles(roles ...string) AuthorizeFunc {
+	return func(_ *gin.Context, principal *Principal) bool {
+		for _, role := range roles {
+			if !principal.HasRole(role) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Auth is authentication middleware for one or more Authenticators.
+// Use it as gin middleware or convert it via MiddlewareFromGin for the Wrapper Options.
+//
+// Authenticators are tried in order until one finds credentials.
+// The authenticated Principal is stored on the gin.Context (see GetPrincipal).
+// Requests without valid credentials receive 401 Unauthorized with WWW-Authenticate challenges
+// and authenticated principals rejected by the AuthorizeFunc receive 403 Forbidden.
+type Auth struct {
+	authenticators []Authenticator
+	authorize      AuthorizeFunc
+}
+
+// NewAuth returns Auth middleware for the specified authenticators.
+// The authorize function is optional.
+func NewAuth(authorize AuthorizeFunc, authenticators ...Authenticator) *Auth {
+	return &Auth{authenticators: authenticators, authorize: authorize}
+}
+
+// Middleware returns a gin middleware function that requires authentication.
+func (a *Auth) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !a.Authenticate(ctx) {
+			ctx.Abort()
+		}
+	}
+}
+
+// Authenticate authenticates and authorizes the request.
+// Returns false if the request is rejected,
+// in which case a 401 Unauthorized or 403 Forbidden response has been written.
+func (a *Auth) Authenticate(ctx *gin.Context) bool {
+	for _, authenticator := range a.authenticators {
+		principal, err := authenticator.Authenticate(ctx.Request)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("path", ctx.Request.URL.Path).Msg("Authentication failed")
+			a.reject(ctx, http.StatusUnauthorized, err, authenticator)
+			return false
+		}
+		if a.authorize != nil && !a.authorize(ctx, principal) {
+			log.Warn().Str("principal", principal.Name).Str("path", ctx.Request.URL.Path).Msg("Authorization failed")
+			a.reject(ctx, http.StatusForbidden, nil, authenticator)
+			return false
+		}
+		ctx.Set(PrincipalKey, principal)
+		return true
+	}
+	a.reject(ctx, http.StatusUnauthorized, ErrNoCredentials, a.authenticators...)
+	return false
+}
+
+// reject writes the error response with challenges from the specified authenticators.
+func (a *Auth) reject(ctx *gin.Context, code int, err error, authenticators ...Authenticator) {
+	for _, authenticator := range authenticators {
+		if challenge := authenticator.Challenge(err); challenge != "" {
+			ctx.Writer.Header().Add(hdrWWWAuthenticate, challenge)
+		}
+	}
+	if code == http.StatusForbidden {
+		_ = ctx.Error(ErrForbidden).SetType(gin.ErrorTypePublic)
+		NegotiatedErrorResult(ctx.Writer, ctx.Request, code, unauthorizedText)
+	} else {
+		_ = ctx.Error(err).SetType(gin.ErrorTypePublic)
+		NegotiatedErrorResult(ctx.Writer, ctx.Request, code, unauthenticatedText)
+	}
+}
+
+// quoteParam returns an auth-param value as a quoted string.
+func quoteParam(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// secretsEqual compares secrets in constant time.
+// The secrets are hashed first so that their lengths are not revealed.
+func secretsEqual(given, expected string) bool {
+	givenHash := sha256.Sum256([]byte(given))
+	expectedHash := sha256.Sum256([]byte(expected))
+	return subtle.ConstantTimeCompare(givenHash[:], expectedHash[:]) == 1
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Make sure the APIKeyAuth struct implements Authenticator.
+var _ = Authenticator(&APIKeyAuth{})
+
+// APIKeyAuth authenticates requests by an API key in a request header.
+// Keys can be rotated by calling SetKeys with both the old and new keys
+// and then again with only the new keys once clients have switched.
+type APIKeyAuth struct {
+	header string
+	keys   map[string]Principal
+	lock   sync.RWMutex
+}
+
+// NewAPIKeyAuth returns an APIKeyAuth for the specified header (defaults to X-API-Key)
+// and map of API keys to principals.
+func NewAPIKeyAuth(header string, keys map[string]Principal) *APIKeyAuth {
+	if header == "" {
+		header = hdrAPIKeyDefault
+	}
+	ak := &APIKeyAuth{header: header}
+	ak.SetKeys(keys)
+	return ak
+}
+
+// SetKeys replaces the map of API keys to principals.
+func (ak *APIKeyAuth) SetKeys(keys map[string]Principal) {
+	copied := make(map[string]Principal, len(keys))
+	for key, principal := range keys {
+		principal.Scheme = authSchemeAPIKey
+		copied[key] = principal
+	}
+	ak.lock.Lock()
+	defer ak.lock.Unlock()
+	ak.keys = copied
+}
+
+// Authenticate returns the principal for the API key in the request header.
+func (ak *APIKeyAuth) Authenticate(request *http.Request) (*Principal, error) {
+	given := request.Header.Get(ak.header)
+	if given == "" {
+		return nil, ErrNoCredentials
+	}
+	ak.lock.RLock()
+	defer ak.lock.RUnlock()
+	var found *Principal
+	// Check every key so that timing doesn't reveal which key matched.
+	for key, principal := range ak.keys {
+		if secretsEqual(given, key) {
+			matched := principal
+			found = &matched
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	return found, nil
+}
+
+// Challenge returns the WWW-Authenticate header value naming the API key header.
+func (ak *APIKeyAuth) Challenge(_ error) string {
+	return authSchemeAPIKey + " header=" + quoteParam(ak.header)
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Make sure the BasicAuth struct implements Authenticator.
+var _ = Authenticator(&BasicAuth{})
+
+// BasicAuth authenticates requests using HTTP Basic authentication.
+// Passwords are compared in constant time.
+type BasicAuth struct {
+	realm string
+	users map[string]string
+}
+
+// NewBasicAuth returns a BasicAuth for the specified realm and map of user names to passwords.
+func NewBasicAuth(realm string, users map[string]string) *BasicAuth {
+	return &BasicAuth{realm: realm, users: users}
+}
+
+// Authenticate returns the principal for the Basic credentials in the request.
+func (ba *BasicAuth) Authenticate(request *http.Request) (*Principal, error) {
+	if !hasScheme(request, authSchemeBasic) {
+		return nil, ErrNoCredentials
+	}
+	user, password, ok := request.BasicAuth()
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed Basic credentials", ErrInvalidCredentials)
+	}
+	expected, found := ba.users[user]
+	// Compare even for unknown users so that timing doesn't reveal valid user names.
+	if !secretsEqual(password, expected) || !found {
+		return nil, fmt.Errorf("%w: bad user name or password", ErrInvalidCredentials)
+	}
+	return &Principal{Name: user, Scheme: authSchemeBasic}, nil
+}
+
+// Challenge returns the WWW-Authenticate header value for the realm.
+func (ba *BasicAuth) Challenge(_ error) string {
+	return authSchemeBasic + " realm=" + quoteParam(ba.realm) + `, charset="UTF-8"`
+}
+
+// hasScheme returns true if the Authorization header uses the specified scheme.
+func hasScheme(request *http.Request, scheme string) bool {
+	authorization := request.Header.Get(hdrAuthorization)
+	return len(authorization) > len(scheme) &&
+		strings.EqualFold(authorization[:len(scheme)], scheme) && authorization[len(scheme)] == ' '
+}
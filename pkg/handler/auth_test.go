@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAuthRequest(hdlrFunc gin.HandlerFunc, header map[string]string, basic ...string) (*httptest.ResponseRecorder, *gin.Context) {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/secret", nil)
+	for key, value := range header {
+		ctx.Request.Header.Set(key, value)
+	}
+	if len(basic) == 2 {
+		ctx.Request.SetBasicAuth(basic[0], basic[1])
+	}
+	hdlrFunc(ctx)
+	return rec, ctx
+}
+
+func TestAuth_APIKey(t *testing.T) {
+	apiKeys := NewAPIKeyAuth("", map[string]Principal{
+		"key-one": {Name: "one", Roles: []string{"admin"}},
+		"key-two": {Name: "two"},
+	})
+	canServe := &wrapped{title: "Secret", text: "Hello, world!"}
+	hdlrFunc := NewWrapped(canServe, Options{
+		Middleware: []Middleware{MiddlewareFromGin(NewAuth(RequireRoles("admin"), apiKeys).Middleware())},
+	}).HandlerFunc()
+
+	rec, ctx := testAuthRequest(hdlrFunc, map[string]string{hdrAPIKeyDefault: "key-one"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), canServe.text)
+	principal, found := GetPrincipal(ctx)
+	require.True(t, found)
+	assert.Equal(t, "one", principal.Name)
+	assert.Equal(t, authSchemeAPIKey, principal.Scheme)
+
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAPIKeyDefault: "key-two"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), unauthorizedText)
+
+	rec, ctx = testAuthRequest(hdlrFunc, map[string]string{hdrAPIKeyDefault: "key-bad"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `APIKey header="X-API-Key"`, rec.Header().Get(hdrWWWAuthenticate))
+	assert.NotContains(t, rec.Body.String(), canServe.text)
+	_, found = GetPrincipal(ctx)
+	assert.False(t, found)
+
+	rec, _ = testAuthRequest(hdlrFunc, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), unauthenticatedText)
+
+	// Rotate keys.
+	apiKeys.SetKeys(map[string]Principal{"key-three": {Name: "three", Roles: []string{"admin"}}})
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAPIKeyDefault: "key-one"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAPIKeyDefault: "key-three"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuth_Basic(t *testing.T) {
+	hdlrFunc := NewAuth(nil, NewBasicAuth("test", map[string]string{"alice": "secret"})).Middleware()
+
+	rec, ctx := testAuthRequest(hdlrFunc, nil, "alice", "secret")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, ctx.IsAborted())
+	principal, found := GetPrincipal(ctx)
+	require.True(t, found)
+	assert.Equal(t, "alice", principal.Name)
+	assert.Equal(t, authSchemeBasic, principal.Scheme)
+
+	for _, credentials := range [][]string{{"alice", "wrong"}, {"bob", "secret"}, {"bob", ""}} {
+		rec, ctx = testAuthRequest(hdlrFunc, nil, credentials...)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.True(t, ctx.IsAborted())
+		assert.Equal(t, `Basic realm="test", charset="UTF-8"`, rec.Header().Get(hdrWWWAuthenticate))
+	}
+
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAuthorization: "Basic !!!"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuth_Multiple(t *testing.T) {
+	hdlrFunc := NewAuth(nil,
+		NewBasicAuth(`the "realm"`, map[string]string{"alice": "secret"}),
+		NewAPIKeyAuth("X-Key", map[string]Principal{"key": {Name: "key"}}),
+	).Middleware()
+
+	rec, ctx := testAuthRequest(hdlrFunc, map[string]string{"X-Key": "key"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	principal, found := GetPrincipal(ctx)
+	require.True(t, found)
+	assert.Equal(t, "key", principal.Name)
+
+	rec, _ = testAuthRequest(hdlrFunc, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, []string{`Basic realm="the \"realm\"", charset="UTF-8"`, `APIKey header="X-Key"`},
+		rec.Header().Values(hdrWWWAuthenticate))
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Make sure the bufferedWriter struct implements gin.ResponseWriter.
+var _ = gin.ResponseWriter(&bufferedWriter{})
+
+// bufferedWriter buffers a response so that it can be inspected or discarded
+// before it is written to the actual response writer.
+type bufferedWriter struct {
+	header    http.Header
+	body      bytes.Buffer
+	status    int
+	written   bool
+	discarded bool
+	lock      sync.Mutex
+}
+
+func newBufferedWriter() *bufferedWriter {
+	return &bufferedWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+// discard marks the writer so that further writes fail and are discarded.
+func (bw *bufferedWriter) discard() {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	bw.discarded = true
+}
+
+// flushTo writes the buffered response to the specified writer.
+func (bw *bufferedWriter) flushTo(writer gin.ResponseWriter) {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	for key, values := range bw.header {
+		writer.Header()[key] = values
+	}
+	if bw.written || bw.body.Len() > 0 {
+		writer.WriteHeader(bw.status)
+		writer.WriteHeaderNow()
+	}
+	if bw.body.Len() > 0 {
+		if _, err := writer.Write(bw.body.Bytes()); err != nil {
+			log.Error().Err(err).Msg("Writing buffered response")
+		}
+	}
+}
+
+// contents returns a copy of the buffered response body.
+func (bw *bufferedWriter) contents() []byte {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	return bytes.Clone(bw.body.Bytes())
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) Write(data []byte) (int, error) {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	if bw.discarded {
+		return 0, http.ErrHandlerTimeout
+	}
+	bw.written = true
+	return bw.body.Write(data)
+}
+
+func (bw *bufferedWriter) WriteString(s string) (int, error) {
+	return bw.Write([]byte(s))
+}
+
+func (bw *bufferedWriter) WriteHeader(code int) {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	if !bw.discarded && !bw.written && code > 0 {
+		bw.status = code
+	}
+}
+
+func (bw *bufferedWriter) WriteHeaderNow() {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	bw.written = true
+}
+
+func (bw *bufferedWriter) Status() int {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	return bw.status
+}
+
+func (bw *bufferedWriter) Size() int {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	if !bw.written {
+		return -1
+	}
+	return bw.body.Len()
+}
+
+func (bw *bufferedWriter) Written() bool {
+	bw.lock.Lock()
+	defer bw.lock.Unlock()
+	return bw.written
+}
+
+// Flush does nothing as the response is buffered.
+func (bw *bufferedWriter) Flush() {}
+
+// Hijack is not supported for buffered responses.
+func (bw *bufferedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, fmt.Errorf("hijack not supported for buffered response")
+}
+
+// CloseNotify returns a channel that never receives,
+// use the request context to detect cancellation instead.
+func (bw *bufferedWriter) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+// Pusher is not supported for buffered responses.
+func (bw *bufferedWriter) Pusher() http.Pusher {
+	return nil
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Caching headers.
+const (
+	hdrCacheControl     = "Cache-Control"
+	hdrETag             = "ETag"
+	hdrIfModifiedSince  = "If-Modified-Since"
+	hdrIfNoneMatch      = "If-None-Match"
+	hdrLastModified     = "Last-Modified"
+	hdrSetCookie        = "Set-Cookie"
+	cacheControlNoStore = "no-store"
+	cacheControlPrivate = "private"
+)
+
+// CachePolicy defines response caching for a handler.
+type CachePolicy struct {
+	// Cache-Control header value for successful responses (e.g. "public, max-age=60").
+	// A Cache-Control header set by the handler itself takes precedence.
+	CacheControl string
+
+	// Optional in-memory store for whole responses.
+	// If nil responses are rendered for every request
+	// but conditional requests still receive 304 Not Modified responses.
+	Store *ResponseCache
+
+	// Request headers that select different representations (e.g. Accept).
+	// These are added to the Vary header and to the key for the Store.
+	KeyHeaders []string
+}
+
+// Cache applies a CachePolicy to GET and HEAD requests.
+// Use it via the Cache field of the Wrapper Options.
+//
+// An ETag is computed over the body of each successful response
+// unless the handler has set one itself.
+// Requests with a matching If-None-Match header,
+// or an If-Modified-Since header no earlier than the Last-Modified time,
+// receive a 304 Not Modified response without a body.
+type Cache struct {
+	policy     CachePolicy
+	keyHeaders []string
+	vary       string
+}
+
+// NewCache returns a Cache object for the specified policy.
+func NewCache(policy CachePolicy) *Cache {
+	c := &Cache{policy: policy}
+	for _, header := range policy.KeyHeaders {
+		c.keyHeaders = append(c.keyHeaders, http.CanonicalHeaderKey(header))
+	}
+	c.vary = strings.Join(c.keyHeaders, ", ")
+	return c
+}
+
+// serve runs the specified function to render the response unless it can be served from the Store.
+// The response is buffered so that the ETag can be computed before it is written.
+func (c *Cache) serve(ctx *gin.Context, render func()) {
+	if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+		render()
+		return
+	}
+
+	key := c.key(ctx)
+	if c.policy.Store != nil {
+		if entry := c.policy.Store.get(key); entry != nil {
+			c.write(ctx, entry)
+			return
+		}
+	}
+
+	writer := ctx.Writer
+	buffer := newBufferedWriter()
+	ctx.Writer = buffer
+	render()
+	ctx.Writer = writer
+
+	if buffer.Status() != http.StatusOK {
+		buffer.flushTo(ctx.Writer)
+		return
+	}
+
+	entry := &cacheEntry{
+		header: buffer.Header().Clone(),
+		body:   buffer.contents(),
+		stored: time.Now(),
+	}
+	// Middleware may have set Cache-Control before the response was buffered.
+	if cacheControl := ctx.Writer.Header().Get(hdrCacheControl); cacheControl != "" {
+		entry.header.Set(hdrCacheControl, cacheControl)
+	} else if c.policy.CacheControl != "" && entry.header.Get(hdrCacheControl) == "" {
+		entry.header.Set(hdrCacheControl, c.policy.CacheControl)
+	}
+	if c.vary != "" {
+		addVary(entry.header, c.vary)
+	}
+	if entry.header.Get(hdrETag) == "" {
+		sum := sha256.Sum256(entry.body)
+		entry.header.Set(hdrETag, `"`+base64.RawURLEncoding.EncodeToString(sum[:16])+`"`)
+	}
+	// Cookies set by middleware are on the writer instead of the buffered header.
+	if c.policy.Store != nil && cacheable(entry.header) && cacheable(ctx.Writer.Header()) {
+		if entry.header.Get(hdrLastModified) == "" {
+			// The stored response doesn't change until it is evicted.
+			entry.header.Set(hdrLastModified, entry.stored.UTC().Format(http.TimeFormat))
+		}
+		c.policy.Store.add(key, entry)
+	}
+	c.write(ctx, entry)
+}
+
+// write writes the entry as the response or a 304 Not Modified response
+// if the conditional request headers match.
+func (c *Cache) write(ctx *gin.Context, entry *cacheEntry) {
+	header := ctx.Writer.Header()
+	for key, values := range entry.header {
+		if key == hdrVary {
+			// Keep Vary values already set, for example by CORS.
+			addVary(header, values...)
+			continue
+		}
+		// Copy values so that later header changes don't affect the stored entry.
+		header[key] = slices.Clone(values)
+	}
+	if notModified(ctx.Request, entry.header) {
+		// Representation headers are not sent with 304 responses.
+		header.Del(hdrContentType)
+		header.Del("Content-Length")
+		ctx.Status(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+	if ctx.Request.Method == http.MethodHead {
+		return
+	}
+	if _, err := ctx.Writer.Write(entry.body); err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+// key returns the Store key for the request.
+func (c *Cache) key(ctx *gin.Context) string {
+	var builder strings.Builder
+	builder.WriteString(ctx.Request.Method)
+	builder.WriteByte(' ')
+	builder.WriteString(ctx.Request.URL.Path)
+	if ctx.Request.URL.RawQuery != "" {
+		builder.WriteByte('?')
+		builder.WriteString(ctx.Request.URL.RawQuery)
+	}
+	for _, name := range c.keyHeaders {
+		builder.WriteByte('\n')
+		builder.WriteString(name)
+		builder.WriteByte(':')
+		builder.WriteString(strings.Join(ctx.Request.Header.Values(name), ","))
+	}
+	return builder.String()
+}
+
+// cacheable returns false if the Cache-Control header forbids shared storage of the response
+// or the response sets cookies, which must never be replayed to other clients.
+func cacheable(header http.Header) bool {
+	if len(header.Values(hdrSetCookie)) > 0 {
+		return false
+	}
+	for _, directive := range strings.Split(header.Get(hdrCacheControl), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if directive == cacheControlNoStore || directive == cacheControlPrivate {
+			return false
+		}
+	}
+	return true
+}
+
+// addVary adds the comma-separated header names to the Vary header,
+// skipping names that are already present.
+func addVary(header http.Header, values ...string) {
+	present := make(map[string]bool)
+	for _, value := range header.Values(hdrVary) {
+		for _, name := range strings.Split(value, ",") {
+			present[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+	}
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !present[strings.ToLower(name)] {
+				header.Add(hdrVary, name)
+				present[strings.ToLower(name)] = true
+			}
+		}
+	}
+}
+
+// notModified returns true if the conditional request headers match the response headers.
+// If-None-Match takes precedence over If-Modified-Since as required by RFC 9110.
+func notModified(request *http.Request, header http.Header) bool {
+	if ifNoneMatch := request.Header.Get(hdrIfNoneMatch); ifNoneMatch != "" {
+		etag := strings.TrimPrefix(header.Get(hdrETag), "W/")
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == corsWildcard || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(request.Header.Get(hdrIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(header.Get(hdrLastModified))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ifModifiedSince)
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// cacheEntry is a complete successful response.
+type cacheEntry struct {
+	key    string
+	header http.Header
+	body   []byte
+	stored time.Time
+}
+
+// ResponseCache is an in-memory least recently used (LRU) cache of responses
+// that can be shared between handlers via CachePolicy.
+type ResponseCache struct {
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	order      *list.List
+	lock       sync.Mutex
+}
+
+// NewResponseCache returns a ResponseCache holding up to maxEntries responses.
+// Responses expire after the specified time to live, zero for no expiration.
+func NewResponseCache(maxEntries int, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Len returns the number of cached responses.
+func (rc *ResponseCache) Len() int {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.order.Len()
+}
+
+// Purge removes all cached responses.
+func (rc *ResponseCache) Purge() {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	clear(rc.entries)
+	rc.order.Init()
+}
+
+// get returns the unexpired entry for the key or nil.
+func (rc *ResponseCache) get(key string) *cacheEntry {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	element, found := rc.entries[key]
+	if !found {
+		return nil
+	}
+	entry := element.Value.(*cacheEntry)
+	if rc.ttl > 0 && time.Since(entry.stored) > rc.ttl {
+		rc.order.Remove(element)
+		delete(rc.entries, key)
+		return nil
+	}
+	rc.order.MoveToFront(element)
+	return entry
+}
+
+// add stores the entry for the key, evicting the least recently used entry if necessary.
+func (rc *ResponseCache) add(key string, entry *cacheEntry) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	entry.key = key
+	if element, found := rc.entries[key]; found {
+		element.Value = entry
+		rc.order.MoveToFront(element)
+		return
+	}
+	rc.entries[key] = rc.order.PushFront(entry)
+	for rc.maxEntries > 0 && rc.order.Len() > rc.maxEntries {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// counted returns a JSON result containing the number of times it has been called.
+type counted struct {
+	calls int
+}
+
+func (c *counted) ServeResult(_ *gin.Context) (any, error) {
+	c.calls++
+	return map[string]int{"calls": c.calls}, nil
+}
+
+func testCacheRequest(hdlrFunc gin.HandlerFunc, method string, header map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(method, "/thing?id=1", nil)
+	for key, value := range header {
+		ctx.Request.Header.Set(key, value)
+	}
+	hdlrFunc(ctx)
+	return rec
+}
+
+func TestCache_ETag(t *testing.T) {
+	hdlrFunc := NewWrapped(&wrapped{title: "Greetings", text: "Hello, world!"}, Options{
+		Cache: NewCache(CachePolicy{CacheControl: "public, max-age=60", KeyHeaders: []string{"accept"}}),
+	}).HandlerFunc()
+	rec := testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Hello, world!")
+	etag := rec.Header().Get(hdrETag)
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=60", rec.Header().Get(hdrCacheControl))
+	assert.Equal(t, "Accept", rec.Header().Get(hdrVary))
+	assert.Empty(t, rec.Header().Get(hdrLastModified))
+
+	// Same body gives the same ETag.
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, etag, rec.Header().Get(hdrETag))
+
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{hdrIfNoneMatch: `"other", ` + etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get(hdrETag))
+	assert.Empty(t, rec.Header().Get(hdrContentType))
+
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{hdrIfNoneMatch: "W/" + etag})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{hdrIfNoneMatch: `"other"`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Hello, world!")
+}
+
+func TestCache_Store(t *testing.T) {
+	store := NewResponseCache(10, time.Hour)
+	canServe := &counted{}
+	hdlrFunc := NewWrappedResult(canServe, Options{
+		Cache: NewCache(CachePolicy{Store: store, KeyHeaders: []string{"Accept"}}),
+	}).HandlerFunc()
+	rec := testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"calls":1}`, rec.Body.String())
+	lastModified := rec.Header().Get(hdrLastModified)
+	require.NotEmpty(t, lastModified)
+	assert.Equal(t, 1, store.Len())
+
+	// Served from the store.
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.JSONEq(t, `{"calls":1}`, rec.Body.String())
+	assert.Equal(t, hdrContentTypeJSONValue, rec.Header().Get(hdrContentType))
+	assert.Equal(t, 1, canServe.calls)
+
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{hdrIfModifiedSince: lastModified})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	earlier := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{hdrIfModifiedSince: earlier})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Key headers and methods select different entries.
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{"Accept": "application/json"})
+	assert.JSONEq(t, `{"calls":2}`, rec.Body.String())
+	rec = testCacheRequest(hdlrFunc, http.MethodHead, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, 3, canServe.calls)
+	assert.Equal(t, 3, store.Len())
+
+	// Other methods are not cached.
+	rec = testCacheRequest(hdlrFunc, http.MethodPost, nil)
+	assert.JSONEq(t, `{"calls":4}`, rec.Body.String())
+	assert.Empty(t, rec.Header().Get(hdrETag))
+	assert.Equal(t, 3, store.Len())
+
+	store.Purge()
+	assert.Equal(t, 0, store.Len())
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.JSONEq(t, `{"calls":5}`, rec.Body.String())
+}
+
+func TestCache_NotStored(t *testing.T) {
+	store := NewResponseCache(10, 0)
+	hdlrFunc := NewWrappedError(&resulted{err: NewStatusError(http.StatusNotFound, "", nil)}, Options{
+		Cache: NewCache(CachePolicy{Store: store}),
+	}).HandlerFunc()
+	rec := testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get(hdrETag))
+	assert.Equal(t, 0, store.Len())
+
+	hdlrFunc = NewWrapped(&wrapped{title: "Private", text: "Secret"}, Options{
+		Middleware: []Middleware{func(ctx *gin.Context, next func()) {
+			ctx.Header(hdrCacheControl, "private, max-age=10")
+			next()
+		}},
+		Cache: NewCache(CachePolicy{CacheControl: "public", Store: store}),
+	}).HandlerFunc()
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "private, max-age=10", rec.Header().Get(hdrCacheControl))
+	assert.NotEmpty(t, rec.Header().Get(hdrETag))
+	assert.Equal(t, 0, store.Len())
+}
+
+// cookieSetter sets a session cookie in its response.
+type cookieSetter struct{}
+
+func (cs *cookieSetter) ServeHTTP(ctx *gin.Context) {
+	ctx.SetCookie("session", "secret", 60, "/", "", true, true)
+	ctx.String(http.StatusOK, "Welcome")
+}
+
+func TestCache_SetCookie(t *testing.T) {
+	store := NewResponseCache(10, 0)
+	hdlrFunc := NewWrapped(&cookieSetter{}, Options{
+		Cache: NewCache(CachePolicy{CacheControl: "public", Store: store}),
+	}).HandlerFunc()
+	rec := testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(hdrSetCookie), "session=secret")
+	assert.Equal(t, 0, store.Len())
+
+	// Cookie set by middleware before the response is buffered.
+	hdlrFunc = NewWrapped(&wrapped{title: "Greetings", text: "Hello, world!"}, Options{
+		Middleware: []Middleware{func(ctx *gin.Context, next func()) {
+			ctx.SetCookie("session", "secret", 60, "/", "", true, true)
+			next()
+		}},
+		Cache: NewCache(CachePolicy{CacheControl: "public", Store: store}),
+	}).HandlerFunc()
+	rec = testCacheRequest(hdlrFunc, http.MethodGet, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(hdrSetCookie), "session=secret")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestCache_VaryMerged(t *testing.T) {
+	store := NewResponseCache(10, 0)
+	hdlrFunc := NewWrapped(&wrapped{title: "Greetings", text: "Hello, world!"}, Options{
+		CORS:  NewCORS(CORSPolicy{AllowOrigins: []string{"https://a.example.com", "https://b.example.com"}}),
+		Cache: NewCache(CachePolicy{Store: store, KeyHeaders: []string{"Accept", "Origin"}}),
+	}).HandlerFunc()
+	for _, origin := range []string{"https://a.example.com", "https://b.example.com", "https://b.example.com"} {
+		rec := testCacheRequest(hdlrFunc, http.MethodGet, map[string]string{hdrOrigin: origin})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []string{"Origin", "Accept"}, rec.Header().Values(hdrVary), origin)
+		assert.Equal(t, origin, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestResponseCache(t *testing.T) {
+	store := NewResponseCache(2, 0)
+	for i := 0; i < 3; i++ {
+		store.add(strconv.Itoa(i), &cacheEntry{stored: time.Now()})
+	}
+	assert.Equal(t, 2, store.Len())
+	assert.Nil(t, store.get("0"))
+	assert.NotNil(t, store.get("1"))
+	store.add("3", &cacheEntry{stored: time.Now()})
+	assert.NotNil(t, store.get("1"))
+	assert.Nil(t, store.get("2"))
+
+	store = NewResponseCache(0, time.Minute)
+	store.add("old", &cacheEntry{stored: time.Now().Add(-time.Hour)})
+	store.add("new", &cacheEntry{stored: time.Now()})
+	assert.Nil(t, store.get("old"))
+	assert.NotNil(t, store.get("new"))
+	assert.Equal(t, 1, store.Len())
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// CORS headers.
+const (
+	hdrOrigin                   = "Origin"
+	hdrVary                     = "Vary"
+	hdrAllowOrigin              = "Access-Control-Allow-Origin"
+	hdrAllowMethods             = "Access-Control-Allow-Methods"
+	hdrAllowHeaders             = "Access-Control-Allow-Headers"
+	hdrAllowCredentials         = "Access-Control-Allow-Credentials"
+	hdrExposeHeaders            = "Access-Control-Expose-Headers"
+	hdrMaxAge                   = "Access-Control-Max-Age"
+	hdrRequestMethod            = "Access-Control-Request-Method"
+	hdrRequestHeaders           = "Access-Control-Request-Headers"
+	corsWildcard                = "*"
+	corsVaryPreflight           = "Origin, Access-Control-Request-Method, Access-Control-Request-Headers"
+	corsDefaultMethods          = "GET, HEAD, POST, PUT, PATCH, DELETE"
+	corsDisallowedPreflightText = "CORS preflight request not allowed"
+)
+
+// CORSPolicy defines a Cross-Origin Resource Sharing (CORS) policy.
+type CORSPolicy struct {
+	// Allowed origins. An entry of "*" allows all origins.
+	// Entries may contain a single "*" wildcard (e.g. "https://*.example.com").
+	// Origins are compared without regard to case.
+	AllowOrigins []string
+
+	// Optional function to allow origins not in AllowOrigins.
+	AllowOriginFunc func(origin string) bool
+
+	// Allowed methods for preflight requests.
+	// Defaults to GET, HEAD, POST, PUT, PATCH, and DELETE.
+	AllowMethods []string
+
+	// Allowed request headers for preflight requests.
+	// An entry of "*" allows any requested headers.
+	// CORS-safelisted headers (e.g. Accept) need not be listed.
+	AllowHeaders []string
+
+	// Response headers that browsers may expose to scripts.
+	ExposeHeaders []string
+
+	// Allow requests with credentials (cookies, authorization headers).
+	// When true the origin is echoed instead of returning "*".
+	// Credentials are not allowed if AllowOrigins contains "*",
+	// which would let any site make authenticated requests,
+	// so list the trusted origins (or use AllowOriginFunc) instead.
+	AllowCredentials bool
+
+	// How long browsers may cache preflight results, zero for browser default.
+	MaxAge time.Duration
+}
+
+// CORS applies a CORSPolicy to requests.
+// Use it as standalone middleware or via the CORS field of the Wrapper Options.
+type CORS struct {
+	policy    CORSPolicy
+	allowAll  bool
+	methods   string
+	headers   []string
+	anyHeader bool
+	expose    string
+	maxAge    string
+}
+
+// NewCORS returns a CORS object for the specified policy.
+// If the policy allows all origins with credentials a warning is logged
+// and credentials are not allowed.
+func NewCORS(policy CORSPolicy) *CORS {
+	allowAll := slices.Contains(policy.AllowOrigins, corsWildcard)
+	if allowAll && policy.AllowCredentials {
+		log.Warn().Msg("CORS credentials not allowed for all origins")
+		policy.AllowCredentials = false
+	}
+	c := &CORS{
+		policy:   policy,
+		allowAll: allowAll,
+		methods:  corsDefaultMethods,
+		expose:   strings.Join(policy.ExposeHeaders, ", "),
+	}
+	if len(policy.AllowMethods) > 0 {
+		methods := make([]string, len(policy.AllowMethods))
+		for i, method := range policy.AllowMethods {
+			methods[i] = strings.ToUpper(method)
+		}
+		c.methods = strings.Join(methods, ", ")
+	}
+	for _, header := range policy.AllowHeaders {
+		if header == corsWildcard {
+			c.anyHeader = true
+		} else {
+			c.headers = append(c.headers, http.CanonicalHeaderKey(header))
+		}
+	}
+	if policy.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(policy.MaxAge / time.Second))
+	}
+	return c
+}
+
+// Middleware returns a gin middleware function that applies the CORS policy.
+// Preflight requests are answered directly and do not reach later handlers,
+// so install the middleware using gin.Engine.Use to handle OPTIONS requests for all routes.
+func (c *CORS) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if c.Handle(ctx) {
+			ctx.Abort()
+		}
+	}
+}
+
+// Handle applies the CORS policy to the request.
+// Returns true if the request was a preflight request which has been answered
+// and no further handling is required.
+func (c *CORS) Handle(ctx *gin.Context) bool {
+	origin := ctx.GetHeader(hdrOrigin)
+	header := ctx.Writer.Header()
+	preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader(hdrRequestMethod) != ""
+	if preflight {
+		header.Add(hdrVary, corsVaryPreflight)
+	} else {
+		header.Add(hdrVary, hdrOrigin)
+	}
+	if origin == "" {
+		// Not a CORS request.
+		return false
+	}
+	if !c.originAllowed(origin) {
+		if preflight {
+			ErrorResult(ctx.Writer, http.StatusForbidden, corsDisallowedPreflightText)
+		}
+		return preflight
+	}
+
+	if c.allowAll && !c.policy.AllowCredentials {
+		header.Set(hdrAllowOrigin, corsWildcard)
+	} else {
+		header.Set(hdrAllowOrigin, origin)
+	}
+	if c.policy.AllowCredentials {
+		header.Set(hdrAllowCredentials, "true")
+	}
+
+	if !preflight {
+		if c.expose != "" {
+			header.Set(hdrExposeHeaders, c.expose)
+		}
+		return false
+	}
+
+	// Preflight request.
+	method := strings.ToUpper(ctx.GetHeader(hdrRequestMethod))
+	if !c.methodAllowed(method) {
+		header.Del(hdrAllowOrigin)
+		header.Del(hdrAllowCredentials)
+		ErrorResult(ctx.Writer, http.StatusForbidden, corsDisallowedPreflightText, "method "+method)
+		return true
+	}
+	requested := ctx.GetHeader(hdrRequestHeaders)
+	if requested != "" {
+		if disallowed := c.disallowedHeader(requested); disallowed != "" {
+			header.Del(hdrAllowOrigin)
+			header.Del(hdrAllowCredentials)
+			ErrorResult(ctx.Writer, http.StatusForbidden, corsDisallowedPreflightText, "header "+disallowed)
+			return true
+		}
+		header.Set(hdrAllowHeaders, requested)
+	}
+	header.Set(hdrAllowMethods, c.methods)
+	if c.maxAge != "" {
+		header.Set(hdrMaxAge, c.maxAge)
+	}
+	ctx.Status(http.StatusNoContent)
+	ctx.Writer.WriteHeaderNow()
+	return true
+}
+
+// originAllowed returns true if the origin is allowed by the policy.
+func (c *CORS) originAllowed(origin string) bool {
+	if c.allowAll {
+		return true
+	}
+	lower := strings.ToLower(origin)
+	for _, allowed := range c.policy.AllowOrigins {
+		allowed = strings.ToLower(allowed)
+		if prefix, suffix, found := strings.Cut(allowed, corsWildcard); found {
+			if len(lower) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(lower, prefix) && strings.HasSuffix(lower, suffix) {
+				return true
+			}
+		} else if lower == allowed {
+			return true
+		}
+	}
+	return c.policy.AllowOriginFunc != nil && c.policy.AllowOriginFunc(origin)
+}
+
+// methodAllowed returns true if the method is allowed by the policy.
+func (c *CORS) methodAllowed(method string) bool {
+	for _, allowed := range strings.Split(c.methods, ", ") {
+		if method == allowed {
+			return true
+		}
+	}
+	// Simple methods are always allowed.
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodPost
+}
+
+// disallowedHeader returns the first requested header that is not allowed, or "" if all are.
+func (c *CORS) disallowedHeader(requested string) string {
+	if c.anyHeader {
+		return ""
+	}
+	for _, name := range strings.Split(requested, ",") {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" && !slices.Contains(c.headers, name) && !corsSafelisted(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// corsSafelisted returns true for CORS-safelisted request headers.
+func corsSafelisted(name string) bool {
+	switch name {
+	case "Accept", "Accept-Language", "Content-Language", "Content-Type":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRouter(policy CORSPolicy) *gin.Engine {
+	router := gin.New()
+	router.Use(NewCORS(policy).Middleware())
+	router.PUT("/thing", func(c *gin.Context) {
+		c.Header("X-Thing-Id", "23")
+		c.String(http.StatusOK, "put")
+	})
+	return router
+}
+
+func corsServe(router http.Handler, method, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	request := httptest.NewRequest(method, "/thing", nil)
+	if origin != "" {
+		request.Header.Set(hdrOrigin, origin)
+	}
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	router.ServeHTTP(rec, request)
+	return rec
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	router := corsRouter(CORSPolicy{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.org"},
+		AllowMethods:     []string{"put", "delete"},
+		AllowHeaders:     []string{"x-custom"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	rec := corsServe(router, http.MethodOptions, "https://shop.example.org", map[string]string{
+		hdrRequestMethod:  "PUT",
+		hdrRequestHeaders: "X-Custom, Content-Type",
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://shop.example.org", rec.Header().Get(hdrAllowOrigin))
+	assert.Equal(t, "PUT, DELETE", rec.Header().Get(hdrAllowMethods))
+	assert.Equal(t, "X-Custom, Content-Type", rec.Header().Get(hdrAllowHeaders))
+	assert.Equal(t, "true", rec.Header().Get(hdrAllowCredentials))
+	assert.Equal(t, "600", rec.Header().Get(hdrMaxAge))
+
+	for _, test := range []struct {
+		origin  string
+		headers map[string]string
+	}{
+		{"https://evil.example.com", map[string]string{hdrRequestMethod: "PUT"}},
+		{"https://example.org", map[string]string{hdrRequestMethod: "PUT"}},
+		{"https://app.example.com", map[string]string{hdrRequestMethod: "PATCH"}},
+		{"https://app.example.com", map[string]string{hdrRequestMethod: "PUT", hdrRequestHeaders: "X-Other"}},
+	} {
+		rec = corsServe(router, http.MethodOptions, test.origin, test.headers)
+		assert.Equal(t, http.StatusForbidden, rec.Code, test)
+		assert.Empty(t, rec.Header().Get(hdrAllowOrigin), test)
+	}
+}
+
+func TestCORS_Request(t *testing.T) {
+	router := corsRouter(CORSPolicy{AllowOrigins: []string{"*"}, ExposeHeaders: []string{"X-Thing-Id"}})
+	rec := corsServe(router, http.MethodPut, "https://anywhere.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get(hdrAllowOrigin))
+	assert.Equal(t, "X-Thing-Id", rec.Header().Get(hdrExposeHeaders))
+	assert.Empty(t, rec.Header().Get(hdrAllowCredentials))
+
+	// Not a CORS request.
+	rec = corsServe(router, http.MethodPut, "", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(hdrAllowOrigin))
+	assert.Equal(t, hdrOrigin, rec.Header().Get(hdrVary))
+
+	// Credentials are never allowed for all origins.
+	router = corsRouter(CORSPolicy{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	rec = corsServe(router, http.MethodPut, "https://anywhere.com", nil)
+	assert.Equal(t, corsWildcard, rec.Header().Get(hdrAllowOrigin))
+	assert.Empty(t, rec.Header().Get(hdrAllowCredentials))
+}
+
+func TestWrapped_CORS(t *testing.T) {
+	router := gin.New()
+	handler := NewWrapped(&wrapped{title: "Greetings", text: "Hello, world!"},
+		Options{CORS: NewCORS(CORSPolicy{AllowOrigins: []string{"https://app.example.com"}})}).HandlerFunc()
+	router.PUT("/thing", handler)
+	router.OPTIONS("/thing", handler)
+	rec := corsServe(router, http.MethodOptions, "https://app.example.com", map[string]string{hdrRequestMethod: "PUT"})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get(hdrAllowOrigin))
+	rec = corsServe(router, http.MethodPut, "https://app.example.com", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get(hdrAllowOrigin))
+	assert.Contains(t, rec.Body.String(), "Hello, world!")
+}
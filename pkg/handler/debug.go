@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// DebugOptions configure the routes registered by RegisterDebugRoutes.
+type DebugOptions struct {
+	// Debug routes are only registered if enabled, usually from system.Config.Debug.
+	Enabled bool
+
+	// Access policy middleware run before every debug handler,
+	// for example Auth.Middleware() or RateLimiter.Middleware().
+	// Defaults to LoopbackOnly.
+	Access []gin.HandlerFunc
+
+	// Optional route index in which the debug routes are described.
+	Index *RouteIndex
+}
+
+// GCResponse is the JSON response from the garbage collection debug route.
+type GCResponse struct {
+	// Heap bytes allocated before and after garbage collection.
+	HeapBefore uint64 `json:"heapBefore"`
+	HeapAfter  uint64 `json:"heapAfter"`
+	// Time taken by garbage collection.
+	Duration string `json:"duration"`
+}
+
+// LoopbackOnly is middleware that only allows requests from loopback addresses,
+// returning 403 Forbidden for all other requests.
+// The remote address of the connection is used instead of any forwarding headers
+// which could be set by the client.
+//
+// Behind a reverse proxy on the same host every request comes from a loopback address,
+// so requests with Forwarded, X-Forwarded-For, or X-Real-IP headers are rejected.
+// Make sure the proxy sets one of these headers or use Auth.Middleware() (or an exit token) instead.
+// The same check applies to ExitWithOptions without a token.
+func LoopbackOnly(c *gin.Context) {
+	if !remoteLoopback(c) {
+		log.Warn().Str("remote", c.Request.RemoteAddr).Str("path", c.Request.URL.Path).Msg("Non-loopback request rejected")
+		NegotiatedErrorResult(c.Writer, c.Request, http.StatusForbidden)
+		c.Abort()
+	}
+}
+
+// RegisterDebugRoutes registers runtime profiling and debug routes with the router group
+// if they are enabled in the options and returns true if the routes were registered.
+//
+// The routes (relative to the group) are:
+//   - GET /pprof/ and /pprof/{profile}: net/http/pprof index and profiles
+//     (including /pprof/profile for CPU profiles and /pprof/trace for execution traces)
+//   - GET /vars: expvar variables as JSON
+//   - GET /goroutines: stack traces of all goroutines as text
+//   - GET /memstats: runtime.MemStats as JSON
+//   - POST /gc: run garbage collection and return a GCResponse
+//
+// Every route is protected by the Access middleware (loopback addresses only by default)
+// since profiles expose program internals and some of them are expensive.
+// The group is normally "/debug", in which case the paths match the standard pprof paths.
+func RegisterDebugRoutes(group *gin.RouterGroup, options DebugOptions) bool {
+	if !options.Enabled {
+		return false
+	}
+	access := options.Access
+	if len(access) == 0 {
+		access = []gin.HandlerFunc{LoopbackOnly}
+	}
+	handle := func(method, relativePath, description string, handler gin.HandlerFunc) {
+		handlers := append(append([]gin.HandlerFunc{}, access...), handler)
+		if options.Index != nil {
+			options.Index.Handle(group, method, relativePath, description, handlers...)
+		} else {
+			group.Handle(method, relativePath, handlers...)
+		}
+	}
+
+	handle(http.MethodGet, "/pprof/", "profile index", gin.WrapF(pprof.Index))
+	handle(http.MethodGet, "/pprof/cmdline", "command line", gin.WrapF(pprof.Cmdline))
+	handle(http.MethodGet, "/pprof/profile", "CPU profile", gin.WrapF(pprof.Profile))
+	handle(http.MethodGet, "/pprof/symbol", "symbol lookup", gin.WrapF(pprof.Symbol))
+	handle(http.MethodPost, "/pprof/symbol", "symbol lookup", gin.WrapF(pprof.Symbol))
+	handle(http.MethodGet, "/pprof/trace", "execution trace", gin.WrapF(pprof.Trace))
+	handle(http.MethodGet, "/pprof/:profile", "named profile", debugProfile)
+	handle(http.MethodGet, "/vars", "expvar variables", gin.WrapH(expvar.Handler()))
+	handle(http.MethodGet, "/goroutines", "goroutine dump", debugGoroutines)
+	handle(http.MethodGet, "/memstats", "memory statistics", debugMemStats)
+	handle(http.MethodPost, "/gc", "run garbage collection", debugGC)
+	log.Info().Str("path", group.BasePath()).Msg("Debug routes enabled")
+	return true
+}
+
+// debugProfile serves a named profile (e.g. heap, goroutine, allocs).
+func debugProfile(c *gin.Context) {
+	pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer, c.Request)
+}
+
+// debugGoroutines writes the stack traces of all goroutines as text.
+func debugGoroutines(c *gin.Context) {
+	c.Header(hdrContentType, hdrContentTypeTextValue)
+	c.Header(hdrContentTypeOptions, hdrContentTypeOptionsValue)
+	c.Status(http.StatusOK)
+	if err := rpprof.Lookup("goroutine").WriteTo(c.Writer, 2); err != nil {
+		log.Logger.Error().Err(err).Msg("Writing goroutine dump")
+	}
+}
+
+// debugMemStats serves the current runtime.MemStats as JSON.
+func debugMemStats(c *gin.Context) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	JSONResult(c.Writer, &stats)
+}
+
+// debugGC runs garbage collection and reports the heap size before and after.
+func debugGC(c *gin.Context) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	response := GCResponse{HeapBefore: stats.HeapAlloc}
+	start := time.Now()
+	runtime.GC()
+	response.Duration = time.Since(start).String()
+	runtime.ReadMemStats(&stats)
+	response.HeapAfter = stats.HeapAlloc
+	log.Info().Str("remote", c.Request.RemoteAddr).Uint64("before", response.HeapBefore).
+		Uint64("after", response.HeapAfter).Msg("Garbage collection requested")
+	JSONResult(c.Writer, response)
+}
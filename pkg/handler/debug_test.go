@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDebugRequest(router *gin.Engine, method, path, remote string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remote
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRegisterDebugRoutes_Disabled(t *testing.T) {
+	router := gin.New()
+	assert.False(t, RegisterDebugRoutes(router.Group("/debug"), DebugOptions{}))
+	assert.Empty(t, router.Routes())
+}
+
+func TestRegisterDebugRoutes(t *testing.T) {
+	router := gin.New()
+	index := NewRouteIndex(router)
+	require.True(t, RegisterDebugRoutes(router.Group("/debug"), DebugOptions{Enabled: true, Index: index}))
+	groups := index.Groups()
+	require.Len(t, groups, 1)
+	assert.Len(t, groups[0].Routes, 11)
+
+	const local = "127.0.0.1:1234"
+	rec := testDebugRequest(router, http.MethodGet, "/debug/pprof/", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "goroutine?debug=1")
+
+	rec = testDebugRequest(router, http.MethodGet, "/debug/pprof/heap?debug=1", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "heap profile")
+
+	rec = testDebugRequest(router, http.MethodGet, "/debug/pprof/cmdline", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = testDebugRequest(router, http.MethodGet, "/debug/vars", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var vars map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &vars))
+	assert.Contains(t, vars, "memstats")
+
+	rec = testDebugRequest(router, http.MethodGet, "/debug/goroutines", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeTextValue, rec.Header().Get(hdrContentType))
+	assert.Contains(t, rec.Body.String(), "goroutine ")
+	assert.Contains(t, rec.Body.String(), "TestRegisterDebugRoutes")
+
+	rec = testDebugRequest(router, http.MethodGet, "/debug/memstats", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var stats map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Contains(t, stats, "HeapAlloc")
+
+	rec = testDebugRequest(router, http.MethodPost, "/debug/gc", local)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var gc GCResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &gc))
+	assert.NotZero(t, gc.HeapBefore)
+	assert.NotEmpty(t, gc.Duration)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/vars", "/debug/memstats"} {
+		rec = testDebugRequest(router, http.MethodGet, path, "192.0.2.1:1234")
+		assert.Equal(t, http.StatusForbidden, rec.Code, path)
+	}
+	rec = testDebugRequest(router, http.MethodPost, "/debug/gc", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Relayed by a reverse proxy on the same host.
+	req := httptest.NewRequest(http.MethodGet, "/debug/memstats", nil)
+	req.RemoteAddr = local
+	req.Header.Set("X-Forwarded-For", "192.0.2.1")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRegisterDebugRoutes_Access(t *testing.T) {
+	auth := NewAuth(nil, NewAPIKeyAuth("", map[string]Principal{"secret": {Name: "ops"}}))
+	router := gin.New()
+	require.True(t, RegisterDebugRoutes(router.Group("/debug"), DebugOptions{
+		Enabled: true,
+		Access:  []gin.HandlerFunc{auth.Middleware()},
+	}))
+
+	rec := testDebugRequest(router, http.MethodGet, "/debug/memstats", "127.0.0.1:1234")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/memstats", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-API-Key", "secret")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// StatusCoder may be implemented by errors that carry an HTTP status code.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Make sure the StatusError struct implements StatusCoder.
+var _ = StatusCoder(&StatusError{})
+
+// StatusError is an error with an HTTP status code and a public message.
+// The wrapped error (if any) is logged but not returned to the client.
+type StatusError struct {
+	Code    int
+	Message string
+	Err     error
+}
+
+// NewStatusError returns a StatusError with the specified status code,
+// public message, and wrapped error (which may be nil).
+func NewStatusError(code int, message string, err error) *StatusError {
+	return &StatusError{Code: code, Message: message, Err: err}
+}
+
+// Error returns the public message and the wrapped error text, if any.
+func (se *StatusError) Error() string {
+	msg := se.Message
+	if msg == "" {
+		msg = http.StatusText(se.Code)
+	}
+	if se.Err != nil {
+		msg += ": " + se.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped error.
+func (se *StatusError) Unwrap() error {
+	return se.Err
+}
+
+// StatusCode returns the HTTP status code.
+func (se *StatusError) StatusCode() int {
+	return se.Code
+}
+
+// ErrorMapping defines the response for a mapped error.
+type ErrorMapping struct {
+	// HTTP status code for the response.
+	Status int
+	// Public message for the response, the status text is used if empty.
+	// The text of the error itself is never returned to the client.
+	Message string
+	// Optional problem type URI for application/problem+json responses.
+	Type string
+}
+
+// ErrorMapper maps errors returned by handlers to responses.
+// Applications register sentinel errors or error types with status codes and public messages.
+// Mappings are checked in the order they were registered.
+// Errors that are not mapped result in an Internal Server Error response.
+type ErrorMapper struct {
+	mappings []errorMatch
+}
+
+// errorMatch pairs an error matching function with a mapping.
+type errorMatch struct {
+	matches func(err error) bool
+	mapping ErrorMapping
+}
+
+// NewErrorMapper returns an empty ErrorMapper.
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{}
+}
+
+// Register maps errors that match the target sentinel error (via errors.Is)
+// to the specified status code and public message.
+// Returns the ErrorMapper so that calls can be chained.
+func (em *ErrorMapper) Register(target error, code int, message string) *ErrorMapper {
+	em.mappings = append(em.mappings, errorMatch{
+		matches: func(err error) bool { return errors.Is(err, target) },
+		mapping: ErrorMapping{Status: code, Message: message},
+	})
+	return em
+}
+
+// RegisterMapping maps errors that satisfy the specified function to the specified mapping.
+// Returns the ErrorMapper so that calls can be chained.
+func (em *ErrorMapper) RegisterMapping(matches func(err error) bool, mapping ErrorMapping) *ErrorMapper {
+	em.mappings = append(em.mappings, errorMatch{matches: matches, mapping: mapping})
+	return em
+}
+
+// RegisterType maps errors of type T (via errors.As) to the specified status code and public message.
+// This is a function rather than a method because methods can't have type parameters.
+func RegisterType[T error](em *ErrorMapper, code int, message string) *ErrorMapper {
+	return em.RegisterMapping(func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}, ErrorMapping{Status: code, Message: message})
+}
+
+// Map returns the mapping for the specified error.
+// Registered mappings are checked first, then the status code is extracted
+// from any StatusCoder in the error chain (see StatusError).
+// The boolean result is false if the error is not mapped,
+// in which case the returned mapping is for an Internal Server Error.
+//
+// Map (and Render) may be called on a nil ErrorMapper,
+// in which case only status code extraction is done.
+func (em *ErrorMapper) Map(err error) (ErrorMapping, bool) {
+	if em != nil {
+		for _, match := range em.mappings {
+			if match.matches(err) {
+				return match.mapping, true
+			}
+		}
+	}
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		return ErrorMapping{Status: statusError.Code, Message: statusError.Message}, true
+	}
+	var statusCoder StatusCoder
+	if errors.As(err, &statusCoder) {
+		return ErrorMapping{Status: statusCoder.StatusCode()}, true
+	}
+	return ErrorMapping{Status: http.StatusInternalServerError}, false
+}
+
+// Render writes the mapped response for the specified error.
+// The response format is negotiated using the request Accept header.
+// The error itself is logged but not returned to the client.
+// Errors that have been added to the gin.Context are logged by the ginzero logger,
+// so they are rendered without logging by the Middleware and wrapped handlers.
+func (em *ErrorMapper) Render(c *gin.Context, err error) {
+	mapping, mapped := em.Map(err)
+	event := log.Warn()
+	if !mapped || mapping.Status >= 500 {
+		event = log.Error()
+	}
+	event.Err(err).Int("code", mapping.Status).Bool("mapped", mapped).
+		Str("path", c.Request.URL.Path).Msg("Handler error")
+	renderMapping(c, mapping)
+}
+
+// renderMapping writes the response for the specified mapping.
+func renderMapping(c *gin.Context, mapping ErrorMapping) {
+	problem := NewProblem(mapping.Status, mapping.Message)
+	problem.Type = mapping.Type
+	NegotiatedProblemResult(c.Writer, c.Request, problem)
+}
+
+// Middleware returns a gin middleware function that renders the mapped response
+// for the last error added to the gin.Context (via gin.Context.Error)
+// if the handler has not already written a response.
+func (em *ErrorMapper) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			mapping, _ := em.Map(c.Errors.Last().Err)
+			renderMapping(c, mapping)
+		}
+	}
+}
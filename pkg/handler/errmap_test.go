@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.New("record 23 not in table secret_users")
+
+type validationError struct {
+	field string
+}
+
+func (ve *validationError) Error() string {
+	return "invalid field " + ve.field
+}
+
+func testErrorMapper() *ErrorMapper {
+	em := NewErrorMapper().Register(errNotFound, http.StatusNotFound, "No such record")
+	return RegisterType[*validationError](em, http.StatusUnprocessableEntity, "Validation failed")
+}
+
+func TestErrorMapper_Map(t *testing.T) {
+	em := testErrorMapper()
+	mapping, mapped := em.Map(fmt.Errorf("lookup: %w", errNotFound))
+	assert.True(t, mapped)
+	assert.Equal(t, http.StatusNotFound, mapping.Status)
+	assert.Equal(t, "No such record", mapping.Message)
+	mapping, mapped = em.Map(fmt.Errorf("save: %w", &validationError{field: "name"}))
+	assert.True(t, mapped)
+	assert.Equal(t, http.StatusUnprocessableEntity, mapping.Status)
+	mapping, mapped = em.Map(errors.New("database on fire"))
+	assert.False(t, mapped)
+	assert.Equal(t, http.StatusInternalServerError, mapping.Status)
+}
+
+func TestErrorMapper_Middleware(t *testing.T) {
+	router := gin.New()
+	router.Use(testErrorMapper().Middleware())
+	router.GET("/missing", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("lookup: %w", errNotFound))
+	})
+	router.GET("/broken", func(c *gin.Context) {
+		_ = c.Error(errors.New("database password is hunter2"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		_ = c.Error(errNotFound)
+		c.String(http.StatusOK, "handled")
+	})
+
+	rec := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	request.Header.Set(hdrAccept, "application/json")
+	router.ServeHTTP(rec, request)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "No such record", problem.Detail)
+	assert.NotContains(t, rec.Body.String(), "secret_users")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/broken", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "hunter2")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/written", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "handled", rec.Body.String())
+}
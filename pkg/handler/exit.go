@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/madkins23/go-utils/server"
+)
+
+// Exit handler headers and parameters.
+const (
+	hdrAllow          = "Allow"
+	hdrExitToken      = "X-Exit-Token"
+	hdrForwarded      = "Forwarded"
+	hdrForwardedFor   = "X-Forwarded-For"
+	hdrRealIP         = "X-Real-IP"
+	exitConfirmParam  = "confirm"
+	exitDefaultDelay  = 500 * time.Millisecond
+	exitDefaultTTL    = time.Minute
+	exitForbiddenText = "Exit not allowed"
+)
+
+// ExitOptions configure the handler returned by ExitWithOptions.
+type ExitOptions struct {
+	// Token required in the X-Exit-Token header.
+	// If empty only requests from loopback addresses are allowed
+	// (see LoopbackOnly for the use of a reverse proxy).
+	Token string
+
+	// Require a second request to confirm the exit.
+	// The first request returns a confirmation token that must be sent back
+	// in the "confirm" query or form parameter within the ConfirmTTL.
+	Confirm bool
+
+	// Time allowed for confirmation, defaults to one minute.
+	ConfirmTTL time.Duration
+
+	// Delay before the process is interrupted so that the response is flushed,
+	// defaults to half a second.
+	Delay time.Duration
+
+	// Function that interrupts the process, defaults to sending SIGINT to the current process.
+	Interrupt func() error
+}
+
+// ExitResponse is the JSON response from the handler returned by ExitWithOptions.
+type ExitResponse struct {
+	// Message describing the result.
+	Message string `json:"message"`
+	// Confirmation token to send back to confirm the exit.
+	Confirm string `json:"confirm,omitempty"`
+	// Time by which the confirmation must be sent.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// ExitWithOptions returns a handler function that will interrupt the current process
+// (via SIGINT by default) after a short delay.
+//
+// Unlike Exit the handler only accepts POST requests
+// and requires either the configured token or a request from a loopback address (see LoopbackOnly),
+// so that crawlers and browser prefetches can't shut down the server.
+// With the Confirm option the first request returns 202 Accepted with a confirmation token
+// and the process is only interrupted by a second request with that token.
+//
+// The handler must be registered for POST (and may be registered for other methods
+// so that they receive 405 Method Not Allowed instead of 404 Not Found).
+func ExitWithOptions(options ExitOptions) gin.HandlerFunc {
+	if options.ConfirmTTL <= 0 {
+		options.ConfirmTTL = exitDefaultTTL
+	}
+	if options.Delay <= 0 {
+		options.Delay = exitDefaultDelay
+	}
+	if options.Interrupt == nil {
+		options.Interrupt = server.Interrupt
+	}
+	var (
+		pending string
+		expires time.Time
+		lock    sync.Mutex
+	)
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Header(hdrAllow, http.MethodPost)
+			NegotiatedErrorResult(c.Writer, c.Request, http.StatusMethodNotAllowed)
+			return
+		}
+		if !exitAllowed(c, options.Token) {
+			log.Warn().Str("remote", c.Request.RemoteAddr).Msg("Exit request rejected")
+			NegotiatedErrorResult(c.Writer, c.Request, http.StatusForbidden, exitForbiddenText)
+			return
+		}
+
+		if options.Confirm {
+			lock.Lock()
+			confirm := c.Query(exitConfirmParam)
+			if confirm == "" {
+				confirm = c.PostForm(exitConfirmParam)
+			}
+			if confirm == "" || pending == "" || time.Now().After(expires) || !secretsEqual(confirm, pending) {
+				pending = exitConfirmToken()
+				expires = time.Now().Add(options.ConfirmTTL)
+				expiresCopy := expires
+				response := ExitResponse{Message: "confirm exit", Confirm: pending, Expires: &expiresCopy}
+				lock.Unlock()
+				c.JSON(http.StatusAccepted, response)
+				return
+			}
+			pending = ""
+			lock.Unlock()
+		}
+
+		log.Info().Str("remote", c.Request.RemoteAddr).Dur("delay", options.Delay).Msg("Exit requested")
+		c.JSON(http.StatusOK, ExitResponse{Message: "exiting"})
+		c.Writer.Flush()
+		time.AfterFunc(options.Delay, func() {
+			if err := options.Interrupt(); err != nil {
+				log.Logger.Error().Err(err).Msg("Unable to interrupt server")
+			}
+		})
+	}
+}
+
+// exitAllowed returns true if the request has the token
+// or, if no token is configured, comes directly from a loopback address (see remoteLoopback).
+func exitAllowed(c *gin.Context, token string) bool {
+	if token != "" {
+		return secretsEqual(c.GetHeader(hdrExitToken), token)
+	}
+	return remoteLoopback(c)
+}
+
+// remoteLoopback returns true if the request connection comes from a loopback address
+// and the request has no forwarding headers, as described for LoopbackOnly.
+func remoteLoopback(c *gin.Context) bool {
+	for _, name := range []string{hdrForwarded, hdrForwardedFor, hdrRealIP} {
+		if c.GetHeader(name) != "" {
+			return false
+		}
+	}
+	ip := net.ParseIP(c.RemoteIP())
+	return ip != nil && ip.IsLoopback()
+}
+
+// exitConfirmToken returns a random confirmation token.
+func exitConfirmToken() string {
+	token := make([]byte, 16)
+	_, _ = rand.Read(token)
+	return hex.EncodeToString(token)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExitRequest(hdlrFunc gin.HandlerFunc, method, target, remote string, header map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(method, target, nil)
+	ctx.Request.RemoteAddr = remote
+	for key, value := range header {
+		ctx.Request.Header.Set(key, value)
+	}
+	hdlrFunc(ctx)
+	return rec
+}
+
+func testExitInterrupt() (func() error, chan bool) {
+	interrupted := make(chan bool, 1)
+	return func() error {
+		interrupted <- true
+		return nil
+	}, interrupted
+}
+
+func TestExitWithOptions_Loopback(t *testing.T) {
+	interrupt, interrupted := testExitInterrupt()
+	hdlrFunc := ExitWithOptions(ExitOptions{Delay: time.Millisecond, Interrupt: interrupt})
+
+	rec := testExitRequest(hdlrFunc, http.MethodGet, "/exit", "127.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get(hdrAllow))
+
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit", "192.0.2.1:1234",
+		map[string]string{"X-Forwarded-For": "127.0.0.1"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), exitForbiddenText)
+
+	select {
+	case <-interrupted:
+		t.Fatal("interrupted by rejected request")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Relayed by a reverse proxy on the same host.
+	for _, name := range []string{hdrForwarded, hdrForwardedFor, hdrRealIP} {
+		rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit", "127.0.0.1:1234",
+			map[string]string{name: "192.0.2.1"})
+		assert.Equal(t, http.StatusForbidden, rec.Code, name)
+	}
+
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit", "[::1]:1234", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"message":"exiting"}`, rec.Body.String())
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second):
+		t.Fatal("not interrupted")
+	}
+}
+
+func TestExitWithOptions_TokenConfirm(t *testing.T) {
+	interrupt, interrupted := testExitInterrupt()
+	hdlrFunc := ExitWithOptions(ExitOptions{Token: "sesame", Confirm: true, Delay: time.Millisecond, Interrupt: interrupt})
+
+	// Loopback is not enough when a token is configured.
+	rec := testExitRequest(hdlrFunc, http.MethodPost, "/exit", "127.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit", "127.0.0.1:1234", map[string]string{hdrExitToken: "wrong"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	token := map[string]string{hdrExitToken: "sesame"}
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit", "192.0.2.1:1234", token)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	var response ExitResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Confirm)
+	require.NotNil(t, response.Expires)
+	assert.True(t, response.Expires.After(time.Now()))
+
+	// Wrong confirmation issues a new confirmation token.
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit?confirm=wrong", "192.0.2.1:1234", token)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit?confirm="+response.Confirm, "192.0.2.1:1234", token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	select {
+	case <-interrupted:
+	case <-time.After(time.Second):
+		t.Fatal("not interrupted")
+	}
+
+	// Confirmation tokens are single use.
+	rec = testExitRequest(hdlrFunc, http.MethodPost, "/exit?confirm="+response.Confirm, "192.0.2.1:1234", token)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
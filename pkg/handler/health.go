@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Health check defaults.
+const (
+	healthDefaultTimeout = 5 * time.Second
+	healthDefaultTTL     = 10 * time.Second
+)
+
+// HealthStatus is the status of a health check or of all health checks.
+type HealthStatus string
+
+const (
+	// HealthUp means that all checks passed.
+	HealthUp HealthStatus = "up"
+	// HealthDegraded means that one or more non-critical checks failed.
+	HealthDegraded HealthStatus = "degraded"
+	// HealthDown means that a critical check failed.
+	HealthDown HealthStatus = "down"
+)
+
+// HealthCheckFunc checks whether a dependency (database, downstream service, etc.) is usable.
+// It should return promptly when the context is done.
+type HealthCheckFunc func(ctxt context.Context) error
+
+// HealthCheck defines a named health check.
+type HealthCheck struct {
+	// Unique name of the check.
+	Name string
+	// Function that performs the check.
+	Check HealthCheckFunc
+	// Maximum time for the check, defaults to five seconds.
+	Timeout time.Duration
+	// Failure of a critical check makes the server unhealthy (503 Service Unavailable),
+	// failure of a non-critical check only makes it degraded.
+	Critical bool
+}
+
+// HealthResult is the result of a single health check.
+type HealthResult struct {
+	Name     string       `json:"name"`
+	Status   HealthStatus `json:"status"`
+	Critical bool         `json:"critical,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	// Time at which the check was run, which may be earlier than the request for cached results.
+	Checked  time.Time `json:"checked"`
+	Duration string    `json:"duration"`
+}
+
+// HealthReport is the aggregate result of all health checks.
+type HealthReport struct {
+	Status HealthStatus   `json:"status"`
+	Checks []HealthResult `json:"checks"`
+}
+
+// Health is a registry of health checks.
+//
+// Checks are run concurrently, each with its own timeout,
+// and results are cached so that frequent probes don't overload dependencies.
+// Concurrent requests share a single run of each check.
+type Health struct {
+	ttl    time.Duration
+	checks map[string]*healthEntry
+	lock   sync.RWMutex
+}
+
+// healthEntry is a registered check with its cached result.
+type healthEntry struct {
+	HealthCheck
+	result  HealthResult
+	expires time.Time
+	lock    sync.Mutex
+}
+
+// NewHealth returns a new, empty health check registry.
+// Check results are cached for the specified time, defaults to ten seconds.
+// Use a negative TTL to disable caching.
+func NewHealth(ttl time.Duration) *Health {
+	if ttl == 0 {
+		ttl = healthDefaultTTL
+	}
+	return &Health{
+		ttl:    ttl,
+		checks: make(map[string]*healthEntry),
+	}
+}
+
+// Register adds the check to the registry, replacing any check with the same name.
+// Returns the Health so that calls can be chained.
+func (h *Health) Register(check HealthCheck) *Health {
+	if check.Timeout <= 0 {
+		check.Timeout = healthDefaultTimeout
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.checks[check.Name] = &healthEntry{HealthCheck: check}
+	return h
+}
+
+// Deregister removes the named check from the registry.
+func (h *Health) Deregister(name string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.checks, name)
+}
+
+// Check runs all registered checks concurrently (or uses cached results)
+// and returns the aggregate report with checks sorted by name.
+// Checks are limited by their own timeouts and are not canceled with the context,
+// since other callers may be waiting for the same run.
+func (h *Health) Check(ctxt context.Context) HealthReport {
+	h.lock.RLock()
+	entries := make([]*healthEntry, 0, len(h.checks))
+	for _, entry := range h.checks {
+		entries = append(entries, entry)
+	}
+	h.lock.RUnlock()
+
+	report := HealthReport{Status: HealthUp, Checks: make([]HealthResult, len(entries))}
+	var wait sync.WaitGroup
+	for i, entry := range entries {
+		wait.Add(1)
+		go func(i int, entry *healthEntry) {
+			defer wait.Done()
+			report.Checks[i] = entry.run(ctxt, h.ttl)
+		}(i, entry)
+	}
+	wait.Wait()
+
+	sort.Slice(report.Checks, func(i, j int) bool { return report.Checks[i].Name < report.Checks[j].Name })
+	for _, result := range report.Checks {
+		if result.Status == HealthDown {
+			if result.Critical {
+				report.Status = HealthDown
+			} else if report.Status == HealthUp {
+				report.Status = HealthDegraded
+			}
+		}
+	}
+	return report
+}
+
+// Handler serves the aggregate health report as JSON.
+// The status code is 503 Service Unavailable if a critical check failed, otherwise 200 OK.
+func (h *Health) Handler(c *gin.Context) {
+	report := h.Check(c.Request.Context())
+	code := http.StatusOK
+	if report.Status == HealthDown {
+		code = http.StatusServiceUnavailable
+	}
+	c.Header(hdrCacheControl, "no-store")
+	c.JSON(code, report)
+}
+
+// run returns the cached result for the check if it is still valid,
+// otherwise runs the check and caches the result.
+// Callers for the same check wait for a single run.
+func (he *healthEntry) run(ctxt context.Context, ttl time.Duration) HealthResult {
+	he.lock.Lock()
+	defer he.lock.Unlock()
+	if time.Now().Before(he.expires) {
+		return he.result
+	}
+
+	// Other callers may be waiting for this run, so a canceled request must not fail the check.
+	start := time.Now()
+	err := he.call(context.WithoutCancel(ctxt))
+	he.result = HealthResult{
+		Name:     he.Name,
+		Status:   HealthUp,
+		Critical: he.Critical,
+		Checked:  start,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		he.result.Status = HealthDown
+		he.result.Error = err.Error()
+		log.Warn().Err(err).Str("check", he.Name).Bool("critical", he.Critical).Msg("Health check failed")
+	}
+	he.expires = start.Add(ttl)
+	return he.result
+}
+
+// call runs the check function with the timeout.
+// The result is returned when the timeout expires even if the function ignores its context.
+func (he *healthEntry) call(ctxt context.Context) error {
+	ctxt, cancel := context.WithTimeout(ctxt, he.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health check panic: %v", r)
+			}
+		}()
+		done <- he.Check(ctxt)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctxt.Done():
+		return fmt.Errorf("health check: %w", ctxt.Err())
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countedCheck returns a check function that counts calls and returns the error.
+func countedCheck(calls *atomic.Int32, err error) HealthCheckFunc {
+	return func(ctxt context.Context) error {
+		calls.Add(1)
+		return err
+	}
+}
+
+func testHealthReport(t *testing.T, health *Health) (int, HealthReport) {
+	router := gin.New()
+	router.GET("/health", health.Handler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, "no-store", rec.Header().Get(hdrCacheControl))
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func TestHealth_Handler(t *testing.T) {
+	var dbCalls, cacheCalls atomic.Int32
+	health := NewHealth(time.Minute).
+		Register(HealthCheck{Name: "db", Critical: true, Check: countedCheck(&dbCalls, nil)}).
+		Register(HealthCheck{Name: "cache", Check: countedCheck(&cacheCalls, errors.New("cache unreachable"))})
+
+	code, report := testHealthReport(t, health)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthDegraded, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "cache", report.Checks[0].Name)
+	assert.Equal(t, HealthDown, report.Checks[0].Status)
+	assert.Equal(t, "cache unreachable", report.Checks[0].Error)
+	assert.False(t, report.Checks[0].Critical)
+	assert.Equal(t, "db", report.Checks[1].Name)
+	assert.Equal(t, HealthUp, report.Checks[1].Status)
+	assert.True(t, report.Checks[1].Critical)
+	assert.Empty(t, report.Checks[1].Error)
+	assert.False(t, report.Checks[1].Checked.IsZero())
+
+	// Cached results.
+	_, again := testHealthReport(t, health)
+	assert.Equal(t, report, again)
+	assert.Equal(t, int32(1), dbCalls.Load())
+	assert.Equal(t, int32(1), cacheCalls.Load())
+
+	health.Register(HealthCheck{Name: "db", Critical: true, Check: countedCheck(&dbCalls, errors.New("db down"))})
+	code, report = testHealthReport(t, health)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthDown, report.Status)
+	assert.Equal(t, "db down", report.Checks[1].Error)
+
+	health.Deregister("db")
+	health.Deregister("cache")
+	code, report = testHealthReport(t, health)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthUp, report.Status)
+	assert.Empty(t, report.Checks)
+}
+
+func TestHealth_Timeout(t *testing.T) {
+	health := NewHealth(-1).
+		Register(HealthCheck{Name: "slow", Critical: true, Timeout: 20 * time.Millisecond,
+			Check: func(ctxt context.Context) error {
+				time.Sleep(time.Second) // ignores the context
+				return nil
+			}}).
+		Register(HealthCheck{Name: "slower", Timeout: 20 * time.Millisecond,
+			Check: func(ctxt context.Context) error {
+				<-ctxt.Done()
+				return ctxt.Err()
+			}})
+	start := time.Now()
+	report := health.Check(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "checks run concurrently and time out")
+	assert.Equal(t, HealthDown, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Contains(t, report.Checks[0].Error, context.DeadlineExceeded.Error())
+	assert.Contains(t, report.Checks[1].Error, context.DeadlineExceeded.Error())
+}
+
+func TestHealth_Panic(t *testing.T) {
+	health := NewHealth(-1).Register(HealthCheck{Name: "panic", Check: func(ctxt context.Context) error {
+		panic("oops")
+	}})
+	report := health.Check(context.Background())
+	assert.Equal(t, HealthDegraded, report.Status)
+	assert.Equal(t, "health check panic: oops", report.Checks[0].Error)
+}
+
+func TestHealth_Concurrent(t *testing.T) {
+	var calls atomic.Int32
+	health := NewHealth(time.Minute).Register(HealthCheck{Name: "shared", Check: func(ctxt context.Context) error {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}})
+	var wait sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			assert.Equal(t, HealthUp, health.Check(context.Background()).Status)
+		}()
+	}
+	wait.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHealth_Canceled(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	health := NewHealth(time.Minute).Register(HealthCheck{Name: "shared", Check: func(ctxt context.Context) error {
+		calls.Add(1)
+		select {
+		case <-release:
+			return nil
+		case <-ctxt.Done():
+			return ctxt.Err()
+		}
+	}})
+
+	// The first request goes away while the check is running.
+	ctxt, cancel := context.WithCancel(context.Background())
+	first := make(chan HealthReport, 1)
+	go func() { first <- health.Check(ctxt) }()
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	second := make(chan HealthReport, 1)
+	go func() { second <- health.Check(context.Background()) }()
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	// The waiting request gets the result of the shared run, not the cancellation.
+	assert.Equal(t, HealthUp, (<-first).Status)
+	assert.Equal(t, HealthUp, (<-second).Status)
+	assert.Equal(t, int32(1), calls.Load())
+}
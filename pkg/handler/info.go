@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readBuildInfo is debug.ReadBuildInfo, replaceable for testing.
+var readBuildInfo = debug.ReadBuildInfo
+
+// Starter reports the time at which a server started, such as a *shutdown.Graceful.
+type Starter interface {
+	Started() time.Time
+}
+
+// InfoOptions configure the handler returned by Info.
+type InfoOptions struct {
+	// Source of the server start time and uptime, usually the *shutdown.Graceful object.
+	Server Starter
+
+	// Configuration values to report, for example from system.Config.Info().
+	// These are shown to anyone who can reach the handler so they must never contain secrets.
+	Config map[string]any
+}
+
+// RuntimeInfo is the JSON response from the handler returned by Info.
+type RuntimeInfo struct {
+	// Main module path and version from the build information.
+	Module  string `json:"module,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Version control revision, time, and whether there were uncommitted changes.
+	Revision     string     `json:"revision,omitempty"`
+	RevisionTime *time.Time `json:"revisionTime,omitempty"`
+	Modified     bool       `json:"modified,omitempty"`
+	// Go version used to build the program.
+	GoVersion string `json:"goVersion"`
+	// Server start time and uptime (rounded to seconds).
+	Started *time.Time `json:"started,omitempty"`
+	Uptime  string     `json:"uptime,omitempty"`
+	// Host name reported by the kernel.
+	Hostname string `json:"hostname,omitempty"`
+	// Selected configuration values.
+	Config map[string]any `json:"config,omitempty"`
+}
+
+// Info returns a handler function that reports build and runtime information
+// so that it is possible to tell which version of a server is deployed.
+//
+// Build information (module version, VCS revision, and modified flag) comes from debug.ReadBuildInfo,
+// which only has VCS information for binaries built with "go build" from a repository.
+// The response is rendered as HTML by default and as JSON if the request Accept header
+// prefers application/json or the request has the query parameter format=json.
+func Info(options InfoOptions) gin.HandlerFunc {
+	static := RuntimeInfo{GoVersion: runtime.Version(), Config: options.Config}
+	if build, ok := readBuildInfo(); ok {
+		static.Module = build.Main.Path
+		static.Version = build.Main.Version
+		static.GoVersion = build.GoVersion
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				static.Revision = setting.Value
+			case "vcs.time":
+				if revisionTime, err := time.Parse(time.RFC3339, setting.Value); err == nil {
+					static.RevisionTime = &revisionTime
+				}
+			case "vcs.modified":
+				static.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		static.Hostname = hostname
+	}
+
+	return func(c *gin.Context) {
+		info := static
+		if options.Server != nil {
+			if started := options.Server.Started(); !started.IsZero() {
+				info.Started = &started
+				info.Uptime = time.Since(started).Round(time.Second).String()
+			}
+		}
+		if c.Query("format") == "json" || negotiate(c.GetHeader(hdrAccept), []string{mediaHTML, mediaJSON}) == mediaJSON {
+			JSONResult(c.Writer, info)
+			return
+		}
+		writePage(c.Writer, http.StatusOK, pageInfo, &pageData{Title: "Info", Fields: info.fields()})
+	}
+}
+
+// fields returns the non-empty information as name/value pairs for the HTML page.
+func (ri *RuntimeInfo) fields() []pageField {
+	var fields []pageField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, pageField{Name: name, Value: value})
+		}
+	}
+	add("Module", ri.Module)
+	add("Version", ri.Version)
+	add("Revision", ri.Revision)
+	if ri.RevisionTime != nil {
+		add("Revision Time", ri.RevisionTime.Format(time.RFC3339))
+	}
+	if ri.Revision != "" {
+		add("Modified", fmt.Sprint(ri.Modified))
+	}
+	add("Go Version", ri.GoVersion)
+	if ri.Started != nil {
+		add("Started", ri.Started.Format(time.RFC3339))
+	}
+	add("Uptime", ri.Uptime)
+	add("Hostname", ri.Hostname)
+	names := make([]string, 0, len(ri.Config))
+	for name := range ri.Config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("Config "+name, fmt.Sprint(ri.Config[name]))
+	}
+	return fields
+}
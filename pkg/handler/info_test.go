@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type startedAt time.Time
+
+func (sa startedAt) Started() time.Time {
+	return time.Time(sa)
+}
+
+func testInfoRouter(t *testing.T, options InfoOptions) *gin.Engine {
+	original := readBuildInfo
+	t.Cleanup(func() { readBuildInfo = original })
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.99",
+			Main:      debug.Module{Path: "example.com/server", Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+				{Key: "vcs.time", Value: "2024-05-06T07:08:09Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+	router := gin.New()
+	router.GET("/info", Info(options))
+	return router
+}
+
+func TestInfo_JSON(t *testing.T) {
+	started := time.Now().Add(-90 * time.Minute)
+	router := testInfoRouter(t, InfoOptions{
+		Server: startedAt(started),
+		Config: map[string]any{"port": 8080},
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info?format=json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var info RuntimeInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, "example.com/server", info.Module)
+	assert.Equal(t, "v1.2.3", info.Version)
+	assert.Equal(t, "abc123", info.Revision)
+	require.NotNil(t, info.RevisionTime)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), info.RevisionTime.UTC())
+	assert.True(t, info.Modified)
+	assert.Equal(t, "go1.99", info.GoVersion)
+	require.NotNil(t, info.Started)
+	assert.True(t, started.Equal(*info.Started))
+	assert.Equal(t, "1h30m0s", info.Uptime)
+	hostname, _ := os.Hostname()
+	assert.Equal(t, hostname, info.Hostname)
+	assert.Equal(t, map[string]any{"port": float64(8080)}, info.Config)
+}
+
+func TestInfo_NotStarted(t *testing.T) {
+	router := testInfoRouter(t, InfoOptions{Server: startedAt(time.Time{})})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/info", nil)
+	req.Header.Set(hdrAccept, mediaJSON)
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "started")
+	assert.NotContains(t, rec.Body.String(), "uptime")
+}
+
+func TestInfo_HTML(t *testing.T) {
+	router := testInfoRouter(t, InfoOptions{Config: map[string]any{"port": 8080, "mode": "<test>"}})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeHTMLValue, rec.Header().Get(hdrContentType))
+	body := rec.Body.String()
+	assert.Contains(t, body, `<td class="name">Revision</td><td class="value">abc123</td>`)
+	assert.Contains(t, body, `<td class="name">Modified</td><td class="value">true</td>`)
+	assert.Contains(t, body, `<td class="name">Config mode</td><td class="value">&lt;test&gt;</td>`)
+	assert.Less(t, strings.Index(body, "Config mode"), strings.Index(body, "Config port"))
+	assert.NotContains(t, body, "Uptime")
+}
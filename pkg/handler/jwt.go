@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWT algorithms.
+const (
+	jwtHS256 = "HS256"
+	jwtRS256 = "RS256"
+)
+
+// JWTOptions configure a JWTAuth.
+// At least one of HMACSecret and JWKSFile must be specified.
+type JWTOptions struct {
+	// Realm for WWW-Authenticate challenges.
+	Realm string
+
+	// Secret for HS256 signed tokens.
+	HMACSecret []byte
+
+	// Path of a local JSON Web Key Set (JWKS) file with RSA public keys for RS256 signed tokens.
+	// Tokens with a kid header use the key with the matching kid.
+	JWKSFile string
+
+	// Required issuer (iss claim), if not empty.
+	Issuer string
+
+	// Required audience (aud claim), if not empty.
+	Audience string
+
+	// Allowed clock skew for the exp and nbf claims.
+	Leeway time.Duration
+}
+
+// Make sure the JWTAuth struct implements Authenticator.
+var _ = Authenticator(&JWTAuth{})
+
+// JWTAuth authenticates requests with a JSON Web Token (JWT) bearer token.
+//
+// The principal Name is the sub claim and the Roles are from
+// the roles claim (array of strings) or the scope claim (space-separated string).
+// Tokens must have an exp claim.
+type JWTAuth struct {
+	options JWTOptions
+	keys    map[string]*rsa.PublicKey
+	lock    sync.RWMutex
+}
+
+// NewJWTAuth returns a JWTAuth with the specified options.
+// Returns an error if neither key is specified or the JWKS file can't be loaded.
+func NewJWTAuth(options JWTOptions) (*JWTAuth, error) {
+	if len(options.HMACSecret) == 0 && options.JWKSFile == "" {
+		return nil, errors.New("no JWT HMAC secret or JWKS file")
+	}
+	ja := &JWTAuth{options: options}
+	if options.JWKSFile != "" {
+		if err := ja.ReloadJWKS(); err != nil {
+			return nil, err
+		}
+	}
+	return ja, nil
+}
+
+// ReloadJWKS reloads the JWKS file, for example after keys have been rotated.
+// The current keys are kept if the file can't be loaded.
+func (ja *JWTAuth) ReloadJWKS() error {
+	data, err := os.ReadFile(ja.options.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("read JWKS file: %w", err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parse JWKS file %s: %w", ja.options.JWKSFile, err)
+	}
+	ja.lock.Lock()
+	defer ja.lock.Unlock()
+	ja.keys = keys
+	return nil
+}
+
+// Authenticate returns the principal for the bearer token in the request.
+func (ja *JWTAuth) Authenticate(request *http.Request) (*Principal, error) {
+	if !hasScheme(request, authSchemeBearer) {
+		return nil, ErrNoCredentials
+	}
+	token := strings.TrimSpace(request.Header.Get(hdrAuthorization)[len(authSchemeBearer)+1:])
+	claims, err := ja.verify(token, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	principal := &Principal{Scheme: authSchemeBearer, Claims: claims}
+	principal.Name, _ = claims["sub"].(string)
+	if roles, ok := claims["roles"].([]any); ok {
+		for _, role := range roles {
+			if name, ok := role.(string); ok {
+				principal.Roles = append(principal.Roles, name)
+			}
+		}
+	} else if scope, ok := claims["scope"].(string); ok {
+		principal.Roles = strings.Fields(scope)
+	}
+	return principal, nil
+}
+
+// Challenge returns the WWW-Authenticate header value for the specified error
+// as defined in RFC 6750.
+func (ja *JWTAuth) Challenge(err error) string {
+	challenge := authSchemeBearer + " realm=" + quoteParam(ja.options.Realm)
+	switch {
+	case err == nil:
+		challenge += `, error="insufficient_scope"`
+	case errors.Is(err, ErrInvalidCredentials):
+		challenge += `, error="invalid_token"`
+	}
+	return challenge
+}
+
+// verify checks the token signature and claims and returns the claims.
+func (ja *JWTAuth) verify(token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("token signature: %w", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case jwtHS256:
+		if len(ja.options.HMACSecret) == 0 {
+			return nil, errors.New("HS256 not configured")
+		}
+		mac := hmac.New(sha256.New, ja.options.HMACSecret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, errors.New("bad signature")
+		}
+	case jwtRS256:
+		key, err := ja.key(header.Kid)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("bad signature")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	claims := make(map[string]any)
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("token claims: %w", err)
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("no expiration")
+	}
+	if now.Add(-ja.options.Leeway).After(time.Unix(int64(exp), 0)) {
+		return nil, errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(ja.options.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("token not yet valid")
+	}
+	if ja.options.Issuer != "" && claims["iss"] != ja.options.Issuer {
+		return nil, errors.New("wrong issuer")
+	}
+	if ja.options.Audience != "" && !audienceMatches(claims["aud"], ja.options.Audience) {
+		return nil, errors.New("wrong audience")
+	}
+	return claims, nil
+}
+
+// key returns the RSA public key for the kid, or the only key if kid is empty.
+func (ja *JWTAuth) key(kid string) (*rsa.PublicKey, error) {
+	ja.lock.RLock()
+	defer ja.lock.RUnlock()
+	if kid == "" && len(ja.keys) == 1 {
+		for _, key := range ja.keys {
+			return key, nil
+		}
+	}
+	if key, found := ja.keys[kid]; found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// audienceMatches returns true if the aud claim (string or array of strings) contains the audience.
+func audienceMatches(aud any, audience string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == audience
+	case []any:
+		return slices.Contains(value, any(audience))
+	}
+	return false
+}
+
+// decodeJWTPart decodes a base64url encoded JSON token part.
+func decodeJWTPart(part string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// parseJWKS returns the RSA signing keys in a JWKS document by kid.
+// Keys of other types or for other uses are ignored.
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != jwtRS256) {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("key %q modulus: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("key %q exponent: %w", jwk.Kid, err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("key %q exponent out of range", jwk.Kid)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no RSA signing keys")
+	}
+	return keys, nil
+}
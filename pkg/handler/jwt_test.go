@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testHMACSecret = []byte("not a very good secret")
+
+func testJWT(t *testing.T, header, claims map[string]any, key any) string {
+	encode := func(part map[string]any) string {
+		data, err := json.Marshal(part)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testJWKSFile(t *testing.T, kid string, key *rsa.PublicKey) string {
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "ignored"},
+		{
+			"kty": "RSA", "kid": kid, "use": "sig", "alg": jwtRS256,
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+	}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestJWTAuth_HS256(t *testing.T) {
+	jwtAuth, err := NewJWTAuth(JWTOptions{Realm: "api", HMACSecret: testHMACSecret, Issuer: "tester", Audience: "gin-utils"})
+	require.NoError(t, err)
+	hdlrFunc := NewAuth(RequireRoles("read"), jwtAuth).Middleware()
+	header := map[string]any{"alg": jwtHS256, "typ": "JWT"}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	token := testJWT(t, header, map[string]any{
+		"sub": "alice", "iss": "tester", "aud": []string{"other", "gin-utils"}, "exp": exp, "scope": "read write",
+	}, testHMACSecret)
+	rec, ctx := testAuthRequest(hdlrFunc, map[string]string{hdrAuthorization: "Bearer " + token})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	principal, found := GetPrincipal(ctx)
+	require.True(t, found)
+	assert.Equal(t, "alice", principal.Name)
+	assert.Equal(t, authSchemeBearer, principal.Scheme)
+	assert.Equal(t, []string{"read", "write"}, principal.Roles)
+	assert.Equal(t, "tester", principal.Claims["iss"])
+
+	// Authenticated but not authorized.
+	token = testJWT(t, header, map[string]any{
+		"sub": "bob", "iss": "tester", "aud": "gin-utils", "exp": exp, "roles": []string{"write"},
+	}, testHMACSecret)
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAuthorization: "Bearer " + token})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, `Bearer realm="api", error="insufficient_scope"`, rec.Header().Get(hdrWWWAuthenticate))
+
+	for name, claims := range map[string]map[string]any{
+		"expired":   {"sub": "x", "iss": "tester", "aud": "gin-utils", "exp": time.Now().Add(-time.Hour).Unix()},
+		"no exp":    {"sub": "x", "iss": "tester", "aud": "gin-utils"},
+		"nbf":       {"sub": "x", "iss": "tester", "aud": "gin-utils", "exp": exp, "nbf": exp},
+		"issuer":    {"sub": "x", "iss": "other", "aud": "gin-utils", "exp": exp},
+		"audience":  {"sub": "x", "iss": "tester", "aud": "other", "exp": exp},
+		"signature": {"sub": "x", "iss": "tester", "aud": "gin-utils", "exp": exp, "secret": true},
+	} {
+		key := testHMACSecret
+		if claims["secret"] == true {
+			key = []byte("wrong secret")
+		}
+		rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAuthorization: "Bearer " + testJWT(t, header, claims, key)})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		assert.Equal(t, `Bearer realm="api", error="invalid_token"`, rec.Header().Get(hdrWWWAuthenticate), name)
+	}
+
+	// Algorithm none is never accepted.
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{
+		hdrAuthorization: "Bearer " + testJWT(t, map[string]any{"alg": "none"}, map[string]any{"sub": "x", "exp": exp}, nil),
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{hdrAuthorization: "Bearer garbage"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, _ = testAuthRequest(hdlrFunc, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="api"`, rec.Header().Get(hdrWWWAuthenticate))
+}
+
+func TestJWTAuth_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwtAuth, err := NewJWTAuth(JWTOptions{JWKSFile: testJWKSFile(t, "key-1", &privateKey.PublicKey)})
+	require.NoError(t, err)
+	hdlrFunc := NewAuth(nil, jwtAuth).Middleware()
+	claims := map[string]any{"sub": "carol", "exp": time.Now().Add(time.Hour).Unix()}
+
+	for _, header := range []map[string]any{{"alg": jwtRS256, "kid": "key-1"}, {"alg": jwtRS256}} {
+		rec, ctx := testAuthRequest(hdlrFunc, map[string]string{hdrAuthorization: "Bearer " + testJWT(t, header, claims, privateKey)})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		principal, found := GetPrincipal(ctx)
+		require.True(t, found)
+		assert.Equal(t, "carol", principal.Name)
+	}
+
+	rec, _ := testAuthRequest(hdlrFunc, map[string]string{
+		hdrAuthorization: "Bearer " + testJWT(t, map[string]any{"alg": jwtRS256, "kid": "key-2"}, claims, privateKey),
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// HS256 is not configured.
+	rec, _ = testAuthRequest(hdlrFunc, map[string]string{
+		hdrAuthorization: "Bearer " + testJWT(t, map[string]any{"alg": jwtHS256}, claims, testHMACSecret),
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestNewJWTAuth_Errors(t *testing.T) {
+	_, err := NewJWTAuth(JWTOptions{})
+	assert.Error(t, err)
+	_, err = NewJWTAuth(JWTOptions{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+	path := filepath.Join(t.TempDir(), "empty.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[]}`), 0o600))
+	_, err = NewJWTAuth(JWTOptions{JWKSFile: path})
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/madkins23/gin-utils/pkg/ginzero"
+)
+
+// LogLevels is the JSON representation of log levels used by GetLogLevels and PutLogLevels.
+// Levels are specified using zerolog level names (e.g. "debug", "info", "warn").
+type LogLevels struct {
+	// Global zerolog level.
+	// For PutLogLevels an empty string leaves the global level unchanged.
+	Global string `json:"global,omitempty"`
+
+	// Subsystem levels keyed by the "sys" field value (e.g. "gin" or "graceful").
+	// For PutLogLevels an empty string removes the level for the subsystem.
+	Sys map[string]string `json:"sys,omitempty"`
+
+	// Time at which the levels will revert to their previous values (GetLogLevels only).
+	Revert *time.Time `json:"revert,omitempty"`
+
+	// Duration after which the levels revert to their previous values (PutLogLevels only).
+	// Specified in time.ParseDuration format (e.g. "5m").
+	TTL string `json:"ttl,omitempty"`
+}
+
+// GetLogLevels returns the current global and subsystem log levels as JSON.
+func GetLogLevels(c *gin.Context) {
+	current, when := ginzero.CurrentLevels()
+	result := LogLevels{Global: current.Global.String()}
+	if len(current.Sys) > 0 {
+		result.Sys = make(map[string]string, len(current.Sys))
+		for sys, level := range current.Sys {
+			result.Sys[sys] = level.String()
+		}
+	}
+	if !when.IsZero() {
+		result.Revert = &when
+	}
+	JSONResult(c.Writer, result)
+}
+
+// PutLogLevels sets the global and subsystem log levels from a JSON LogLevels request body.
+// If a TTL is provided the levels revert to their previous values after that duration.
+// The resulting levels are returned as from GetLogLevels.
+//
+// The handler performs no authorization itself,
+// so it must be registered behind an access policy (e.g. Auth.Middleware() or LoopbackOnly).
+// Otherwise anyone who can reach the server can set all levels to trace and flood the logs.
+func PutLogLevels(c *gin.Context) {
+	var request LogLevels
+	if err := json.NewDecoder(c.Request.Body).Decode(&request); err != nil {
+		ErrorResult(c.Writer, http.StatusBadRequest, "Unable to parse log levels: "+err.Error())
+		return
+	}
+
+	changes := ginzero.Levels{Global: zerolog.NoLevel}
+	if request.Global != "" {
+		if level, err := zerolog.ParseLevel(request.Global); err != nil {
+			ErrorResult(c.Writer, http.StatusBadRequest, "Bad global level: "+err.Error())
+			return
+		} else {
+			changes.Global = level
+		}
+	}
+	if len(request.Sys) > 0 {
+		changes.Sys = make(map[string]zerolog.Level, len(request.Sys))
+		for sys, name := range request.Sys {
+			if level, err := zerolog.ParseLevel(name); err != nil {
+				ErrorResult(c.Writer, http.StatusBadRequest, "Bad level for "+sys+": "+err.Error())
+				return
+			} else {
+				changes.Sys[sys] = level
+			}
+		}
+	}
+	var ttl time.Duration
+	if request.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl < 0 {
+			ErrorResult(c.Writer, http.StatusBadRequest, "Bad TTL: "+request.TTL)
+			return
+		}
+	}
+
+	ginzero.SetLevels(changes, ttl)
+	log.Info().Str("global", request.Global).Interface("sys", request.Sys).Str("ttl", request.TTL).Msg("Log levels changed")
+	GetLogLevels(c)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/gin-utils/pkg/ginzero"
+)
+
+func TestLogLevels(t *testing.T) {
+	global := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(global)
+	defer ginzero.SetLevels(ginzero.Levels{
+		Global: zerolog.NoLevel,
+		Sys:    map[string]zerolog.Level{"gin": zerolog.NoLevel},
+	}, 0)
+
+	router := gin.New()
+	router.GET("/levels", GetLogLevels)
+	router.PUT("/levels", PutLogLevels)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/levels",
+		strings.NewReader(`{"global":"info","sys":{"gin":"warn"},"ttl":"1h"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/levels", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var levels LogLevels
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &levels))
+	assert.Equal(t, "info", levels.Global)
+	assert.Equal(t, map[string]string{"gin": "warn"}, levels.Sys)
+	assert.NotNil(t, levels.Revert)
+
+	for _, body := range []string{`{"global":"loud"}`, `{"sys":{"gin":"quiet"}}`, `{"ttl":"soon"}`, `not json`} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/levels", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+}
@@ -0,0 +1,477 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPI constants.
+const (
+	openAPIVersion          = "3.0.3"
+	hdrContentTypeYAMLValue = "application/yaml; charset=utf-8"
+)
+
+// Operation documents a route in the OpenAPI document.
+//
+// Request and Response are example values (usually zero values) of the request and response types.
+// Request struct fields with uri, form, and header tags are documented as path, query,
+// and header parameters; other JSON fields make up the request body (as for Typed).
+// Fields with a binding:"required" tag are required
+// and description tags are used as field descriptions.
+type Operation struct {
+	// Short summary of the operation.
+	Summary string
+	// Longer description of the operation.
+	Description string
+	// Tags for grouping operations.
+	Tags []string
+	// Unique operation ID, optional.
+	OperationID string
+	// Request value, optional.
+	Request any
+	// Successful response body value, optional.
+	Response any
+	// Successful response status code, defaults to 200 OK (204 No Content if there is a Request but no Response).
+	Status int
+	// Error status codes and descriptions, returned as application/problem+json.
+	Errors map[int]string
+}
+
+// OpenAPIInfo is the info section of the OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPI generates an OpenAPI 3 document for the routes configured in a gin.Engine.
+// The document is built from gin.Engine.Routes() when it is served
+// so it never drifts from the configured routes.
+// Operations are registered alongside routes via Handle or HandleWrapped or added via Describe;
+// routes without operations are documented with only their path parameters.
+type OpenAPI struct {
+	engine     *gin.Engine
+	info       OpenAPIInfo
+	operations map[string]*Operation
+	lock       sync.RWMutex
+}
+
+// NewOpenAPI returns an OpenAPI generator for the specified engine.
+func NewOpenAPI(engine *gin.Engine, info OpenAPIInfo) *OpenAPI {
+	return &OpenAPI{
+		engine:     engine,
+		info:       info,
+		operations: make(map[string]*Operation),
+	}
+}
+
+// Describe adds the operation for the route with the specified method and full path.
+// Returns the OpenAPI so that calls can be chained.
+func (oa *OpenAPI) Describe(method, fullPath string, operation *Operation) *OpenAPI {
+	oa.lock.Lock()
+	defer oa.lock.Unlock()
+	oa.operations[routeKey(method, fullPath)] = operation
+	return oa
+}
+
+// Handle registers the handlers for the method and relative path with the router
+// and adds the operation for the route.
+func (oa *OpenAPI) Handle(router RouteRegistrar, method, relativePath string, operation *Operation, handlers ...gin.HandlerFunc) {
+	router.Handle(method, relativePath, handlers...)
+	oa.Describe(method, joinRoutePaths(router.BasePath(), relativePath), operation)
+}
+
+// HandleWrapped registers the wrapped handler for the method and relative path with the router
+// and adds the Operation from the wrapper Options for the route.
+func (oa *OpenAPI) HandleWrapped(router RouteRegistrar, method, relativePath string, wrapper *Wrapper) {
+	oa.Handle(router, method, relativePath, wrapper.options.Operation, wrapper.HandlerFunc())
+}
+
+// Document returns the OpenAPI document as JSON.
+func (oa *OpenAPI) Document() ([]byte, error) {
+	return json.MarshalIndent(oa.document(), "", "  ")
+}
+
+// DocumentYAML returns the OpenAPI document as YAML.
+func (oa *OpenAPI) DocumentYAML() ([]byte, error) {
+	data, err := json.Marshal(oa.document())
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML so decoding it to a node keeps the key order,
+	// after which block style is used for readability.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("convert JSON to YAML: %w", err)
+	}
+	clearYAMLStyle(&node)
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("encode YAML: %w", err)
+	}
+	return buffer.Bytes(), encoder.Close()
+}
+
+// JSONHandler serves the OpenAPI document as JSON.
+func (oa *OpenAPI) JSONHandler(c *gin.Context) {
+	data, err := oa.Document()
+	if err != nil {
+		_ = c.Error(err)
+		NegotiatedErrorResult(c.Writer, c.Request, http.StatusInternalServerError, "Generating OpenAPI document")
+		return
+	}
+	c.Data(http.StatusOK, hdrContentTypeJSONValue, data)
+}
+
+// YAMLHandler serves the OpenAPI document as YAML.
+func (oa *OpenAPI) YAMLHandler(c *gin.Context) {
+	data, err := oa.DocumentYAML()
+	if err != nil {
+		_ = c.Error(err)
+		NegotiatedErrorResult(c.Writer, c.Request, http.StatusInternalServerError, "Generating OpenAPI document")
+		return
+	}
+	c.Data(http.StatusOK, hdrContentTypeYAMLValue, data)
+}
+
+// ViewerHandler serves an HTML page describing the OpenAPI document.
+// The page is rendered on the server and needs no external scripts or style sheets.
+func (oa *OpenAPI) ViewerHandler(c *gin.Context) {
+	doc := oa.document()
+	writePage(c.Writer, http.StatusOK, pageOpenAPI, &pageData{
+		Title:      doc.Info.Title,
+		StyleSheet: RouteIndexStyleSheet,
+		API:        openAPIViews(doc),
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// openAPIDocument is an OpenAPI 3 document (the subset generated from routes).
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*openAPISchema `json:"schemas"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	OperationID string                      `json:"operationId,omitempty"`
+	Parameters  []*openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+// parameterTags maps request struct tags to OpenAPI parameter locations.
+var parameterTags = []struct{ tag, in string }{
+	{"uri", "path"}, {"form", "query"}, {"header", "header"},
+}
+
+// document builds the OpenAPI document for the current routes.
+func (oa *OpenAPI) document() *openAPIDocument {
+	oa.lock.RLock()
+	defer oa.lock.RUnlock()
+	builder := newSchemaBuilder()
+	doc := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    oa.info,
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+	for _, route := range oa.engine.Routes() {
+		path, pathParams := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*openAPIOperation)
+		}
+		operation := oa.operations[routeKey(route.Method, route.Path)]
+		if operation == nil {
+			operation = &Operation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = operation.build(builder, route.Method, pathParams)
+	}
+	doc.Components.Schemas = builder.components
+	return doc
+}
+
+// build returns the OpenAPI operation object.
+func (op *Operation) build(builder *schemaBuilder, method string, pathParams []string) *openAPIOperation {
+	result := &openAPIOperation{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.OperationID,
+		Responses:   make(map[string]*openAPIResponse),
+	}
+
+	documented := make(map[string]bool)
+	if op.Request != nil {
+		typ := reflect.TypeOf(op.Request)
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.Struct {
+			result.Parameters = requestParameters(builder, typ, documented)
+			if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
+				body := builder.structSchema(typ, isParameterField)
+				if len(body.Properties) > 0 {
+					result.RequestBody = &openAPIRequestBody{
+						Required: true,
+						Content:  map[string]*openAPIMediaType{mediaJSON: {Schema: body}},
+					}
+				}
+			}
+		}
+	}
+	// Path parameters not in the request struct.
+	for _, name := range pathParams {
+		if !documented["path:"+name] {
+			result.Parameters = append(result.Parameters, &openAPIParameter{
+				Name: name, In: "path", Required: true, Schema: &openAPISchema{Type: "string"},
+			})
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+		if op.Request != nil && op.Response == nil {
+			status = http.StatusNoContent
+		}
+	}
+	success := &openAPIResponse{Description: http.StatusText(status)}
+	if op.Response != nil {
+		success.Content = map[string]*openAPIMediaType{
+			mediaJSON: {Schema: builder.schema(reflect.TypeOf(op.Response))},
+		}
+	}
+	result.Responses[strconv.Itoa(status)] = success
+	for code, description := range op.Errors {
+		if description == "" {
+			description = http.StatusText(code)
+		}
+		result.Responses[strconv.Itoa(code)] = &openAPIResponse{
+			Description: description,
+			Content: map[string]*openAPIMediaType{
+				mediaProblem: {Schema: &openAPISchema{Ref: "#/components/schemas/Problem"}},
+			},
+		}
+	}
+	return result
+}
+
+// requestParameters returns the parameters for request struct fields with uri, form, or header tags.
+// Documented parameters are recorded as "in:name".
+func requestParameters(builder *schemaBuilder, typ reflect.Type, documented map[string]bool) []*openAPIParameter {
+	var parameters []*openAPIParameter
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		for _, pt := range parameterTags {
+			name, _, _ := strings.Cut(field.Tag.Get(pt.tag), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			parameters = append(parameters, &openAPIParameter{
+				Name:        name,
+				In:          pt.in,
+				Description: field.Tag.Get("description"),
+				Required:    pt.in == "path" || fieldRequired(field),
+				Schema:      builder.schema(field.Type),
+			})
+			documented[pt.in+":"+name] = true
+		}
+	}
+	return parameters
+}
+
+// isParameterField returns true for request struct fields bound from parameters instead of the body.
+func isParameterField(field reflect.StructField) bool {
+	for _, pt := range parameterTags {
+		if name, _, _ := strings.Cut(field.Tag.Get(pt.tag), ","); name != "" && name != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIPath converts a gin route path (e.g. "/things/:id") to an OpenAPI path ("/things/{id}")
+// and returns the names of the path parameters.
+func openAPIPath(ginPath string) (string, []string) {
+	segments := strings.Split(ginPath, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// clearYAMLStyle resets the style of all nodes so that block style is used.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// openAPIView is the data for the OpenAPI viewer page.
+type openAPIView struct {
+	Info       OpenAPIInfo
+	Version    string
+	Operations []openAPIOperationView
+	Schemas    []openAPISchemaView
+}
+
+// openAPIOperationView is a single operation on the OpenAPI viewer page.
+type openAPIOperationView struct {
+	Method     string
+	Path       string
+	Operation  *openAPIOperation
+	Body       string
+	Responses  []openAPIResponseView
+	Parameters []*openAPIParameter
+}
+
+// openAPISchemaView is a single component schema on the OpenAPI viewer page.
+type openAPISchemaView struct {
+	Name       string
+	Properties []openAPIPropertyView
+}
+
+// openAPIPropertyView is a single schema property on the OpenAPI viewer page.
+type openAPIPropertyView struct {
+	Name        string
+	Schema      string
+	Required    bool
+	Description string
+}
+
+// openAPIResponseView is a single response on the OpenAPI viewer page.
+type openAPIResponseView struct {
+	Code        string
+	Description string
+	Schema      string
+}
+
+// openAPIViews returns the data for the OpenAPI viewer page with operations sorted by path and method.
+func openAPIViews(doc *openAPIDocument) *openAPIView {
+	view := &openAPIView{Info: doc.Info, Version: doc.OpenAPI}
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			opView := openAPIOperationView{
+				Method:     strings.ToUpper(method),
+				Path:       path,
+				Operation:  operation,
+				Parameters: operation.Parameters,
+			}
+			if operation.RequestBody != nil {
+				opView.Body = schemaText(operation.RequestBody.Content[mediaJSON].Schema)
+			}
+			for code, response := range operation.Responses {
+				responseView := openAPIResponseView{Code: code, Description: response.Description}
+				for _, mediaType := range response.Content {
+					responseView.Schema = schemaText(mediaType.Schema)
+				}
+				opView.Responses = append(opView.Responses, responseView)
+			}
+			sort.Slice(opView.Responses, func(i, j int) bool { return opView.Responses[i].Code < opView.Responses[j].Code })
+			view.Operations = append(view.Operations, opView)
+		}
+	}
+	sort.Slice(view.Operations, func(i, j int) bool {
+		if view.Operations[i].Path != view.Operations[j].Path {
+			return view.Operations[i].Path < view.Operations[j].Path
+		}
+		return methodOrder(view.Operations[i].Method) < methodOrder(view.Operations[j].Method)
+	})
+	for name, schema := range doc.Components.Schemas {
+		schemaView := openAPISchemaView{Name: name}
+		for propName, property := range schema.Properties {
+			schemaView.Properties = append(schemaView.Properties, openAPIPropertyView{
+				Name:        propName,
+				Schema:      schemaText(property),
+				Required:    slices.Contains(schema.Required, propName),
+				Description: property.Description,
+			})
+		}
+		sort.Slice(schemaView.Properties, func(i, j int) bool {
+			return schemaView.Properties[i].Name < schemaView.Properties[j].Name
+		})
+		view.Schemas = append(view.Schemas, schemaView)
+	}
+	sort.Slice(view.Schemas, func(i, j int) bool { return view.Schemas[i].Name < view.Schemas[j].Name })
+	return view
+}
+
+// schemaText returns a short description of a schema for the viewer page.
+func schemaText(schema *openAPISchema) string {
+	switch {
+	case schema == nil:
+		return ""
+	case schema.Ref != "":
+		return strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+	case schema.Type == "array":
+		return "[]" + schemaText(schema.Items)
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		return "map[string]" + schemaText(schema.AdditionalProperties)
+	case schema.Type == "object":
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "{" + strings.Join(names, ", ") + "}"
+	case schema.Format != "":
+		return schema.Type + " (" + schema.Format + ")"
+	case schema.Type == "":
+		return "any"
+	default:
+		return schema.Type
+	}
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openAPISchema is an OpenAPI 3 schema object (the subset generated from Go types).
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+}
+
+// openAPIProblemSchema is the schema for Problem responses.
+var openAPIProblemSchema = &openAPISchema{
+	Type: "object",
+	Properties: map[string]*openAPISchema{
+		"type":     {Type: "string", Format: "uri"},
+		"title":    {Type: "string"},
+		"status":   {Type: "integer"},
+		"detail":   {Type: "string"},
+		"instance": {Type: "string"},
+	},
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	schemaNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemaBuilder builds schemas for Go types,
+// collecting named struct types as components so that recursive types work.
+type schemaBuilder struct {
+	components map[string]*openAPISchema
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: map[string]*openAPISchema{"Problem": openAPIProblemSchema},
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schema returns the schema for the type.
+func (sb *schemaBuilder) schema(typ reflect.Type) *openAPISchema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case reflect.PointerTo(typ).Implements(textMarshalerType):
+		return &openAPISchema{Type: "string"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &openAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: sb.schema(typ.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: sb.schema(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return sb.structSchema(typ, nil)
+		}
+		return sb.ref(typ)
+	default:
+		// Interfaces and anything else can be any value.
+		return &openAPISchema{}
+	}
+}
+
+// ref returns a reference to the component schema for a named struct type,
+// adding the component if necessary.
+func (sb *schemaBuilder) ref(typ reflect.Type) *openAPISchema {
+	name, found := sb.names[typ]
+	if !found {
+		name = schemaNameInvalid.ReplaceAllString(typ.Name(), "_")
+		for i := 2; sb.components[name] != nil; i++ {
+			// Same name in a different package.
+			name = schemaNameInvalid.ReplaceAllString(typ.Name(), "_") + "_" + strconv.Itoa(i)
+		}
+		sb.names[typ] = name
+		// Placeholder for recursive references.
+		sb.components[name] = &openAPISchema{}
+		*sb.components[name] = *sb.structSchema(typ, nil)
+	}
+	return &openAPISchema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema returns an object schema for the JSON fields of the struct type.
+// The skip function (if not nil) excludes fields.
+func (sb *schemaBuilder) structSchema(typ reflect.Type, skip func(field reflect.StructField) bool) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	for _, field := range reflect.VisibleFields(typ) {
+		if !field.IsExported() || (skip != nil && skip(field)) {
+			continue
+		}
+		name, embedded := jsonFieldName(field)
+		if name == "" || embedded || !promotedByJSON(typ, field) {
+			// Omitted, or an embedded struct whose fields are promoted.
+			continue
+		}
+		property := sb.schema(field.Type)
+		if description := field.Tag.Get("description"); description != "" && property.Ref == "" {
+			property.Description = description
+		}
+		schema.Properties[name] = property
+		if fieldRequired(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// jsonFieldName returns the JSON name for the field, "" if it is omitted,
+// and whether it is an untagged embedded struct (whose fields are promoted).
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		if field.Anonymous {
+			typ := field.Type
+			if typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			if typ.Kind() == reflect.Struct {
+				return field.Name, true
+			}
+		}
+		name = field.Name
+	}
+	return name, false
+}
+
+// promotedByJSON returns false if the field is promoted from an embedded struct
+// that encoding/json treats as a named field instead (because it has a JSON name).
+func promotedByJSON(typ reflect.Type, field reflect.StructField) bool {
+	for depth := 1; depth < len(field.Index); depth++ {
+		if _, embedded := jsonFieldName(typ.FieldByIndex(field.Index[:depth])); !embedded {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldRequired returns true if the field has the binding:"required" validation tag.
+func fieldRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
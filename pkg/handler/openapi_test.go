@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type openAPINode struct {
+	Name     string         `json:"name" description:"node name"`
+	Children []*openAPINode `json:"children,omitempty"`
+	Hidden   string         `json:"-"`
+}
+
+func testOpenAPI() (*gin.Engine, *OpenAPI) {
+	router := gin.New()
+	api := NewOpenAPI(router, OpenAPIInfo{Title: "Things <API>", Version: "1.2.3"})
+	api.Handle(router, http.MethodGet, "/ping", &Operation{Summary: "server existence"}, Ping)
+	group := router.Group("/api")
+	api.HandleWrapped(group, http.MethodPost, "/thing/:id",
+		NewTyped(func(_ context.Context, request typedRequest) (typedResponse, error) {
+			return typedResponse{ID: request.ID}, nil
+		}, Options{Operation: &Operation{
+			Summary: "create a thing",
+			Tags:    []string{"things"},
+			Errors:  map[int]string{http.StatusNotFound: ""},
+		}}))
+	group.GET("/tree/*path", Ping)
+	api.Describe(http.MethodGet, "/api/tree/*path", &Operation{Response: openAPINode{}})
+	router.GET("/openapi.json", api.JSONHandler)
+	return router, api
+}
+
+func TestOpenAPI_Document(t *testing.T) {
+	router, api := testOpenAPI()
+	data, err := api.Document()
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, openAPIVersion, doc["openapi"])
+	assert.Equal(t, map[string]any{"title": "Things <API>", "version": "1.2.3"}, doc["info"])
+
+	paths := doc["paths"].(map[string]any)
+	assert.Len(t, paths, 4)
+	assert.Contains(t, paths, "/openapi.json")
+	ping := paths["/ping"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, "server existence", ping["summary"])
+	assert.Contains(t, ping["responses"], "200")
+
+	post := paths["/api/thing/{id}"].(map[string]any)["post"].(map[string]any)
+	assert.Equal(t, []any{"things"}, post["tags"])
+	params := post["parameters"].([]any)
+	require.Len(t, params, 3)
+	assert.Equal(t, map[string]any{"name": "id", "in": "path", "required": true,
+		"schema": map[string]any{"type": "integer", "format": "int32"}}, params[0])
+	assert.Equal(t, map[string]any{"name": "verbose", "in": "query",
+		"schema": map[string]any{"type": "boolean"}}, params[1])
+	assert.Equal(t, map[string]any{"name": "X-Tenant", "in": "header", "required": true,
+		"schema": map[string]any{"type": "string"}}, params[2])
+	body := post["requestBody"].(map[string]any)["content"].(map[string]any)[mediaJSON].(map[string]any)["schema"].(map[string]any)
+	assert.Equal(t, []any{"name"}, body["required"])
+	properties := body["properties"].(map[string]any)
+	assert.Len(t, properties, 2)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/typedAddress"}, properties["address"])
+	responses := post["responses"].(map[string]any)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/typedResponse"},
+		responses["200"].(map[string]any)["content"].(map[string]any)[mediaJSON].(map[string]any)["schema"])
+	assert.Equal(t, map[string]any{"description": "Not Found", "content": map[string]any{
+		mediaProblem: map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Problem"}},
+	}}, responses["404"])
+
+	tree := paths["/api/tree/{path}"].(map[string]any)["get"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"name": "path", "in": "path", "required": true,
+		"schema": map[string]any{"type": "string"}}}, tree["parameters"])
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Contains(t, schemas, "Problem")
+	assert.Equal(t, map[string]any{"type": "object", "properties": map[string]any{
+		"name":     map[string]any{"type": "string", "description": "node name"},
+		"children": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/openAPINode"}},
+	}}, schemas["openAPINode"])
+	assert.Equal(t, []any{"city"}, schemas["typedAddress"].(map[string]any)["required"])
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeJSONValue, rec.Header().Get(hdrContentType))
+	assert.JSONEq(t, string(data), rec.Body.String())
+}
+
+func TestOpenAPI_DocumentYAML(t *testing.T) {
+	router, api := testOpenAPI()
+	router.GET("/openapi.yaml", api.YAMLHandler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeYAMLValue, rec.Header().Get(hdrContentType))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "openapi: "+openAPIVersion+"\n"))
+	assert.Contains(t, rec.Body.String(), "\npaths:\n  /api/thing/{id}:\n    post:\n")
+	assert.NotContains(t, rec.Body.String(), ": {", "block style")
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Contains(t, doc["paths"], "/api/thing/{id}")
+}
+
+func TestOpenAPI_ViewerHandler(t *testing.T) {
+	router, api := testOpenAPI()
+	router.GET("/openapi", api.ViewerHandler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeHTMLValue, rec.Header().Get(hdrContentType))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<title>Things &lt;API&gt;</title>")
+	assert.Contains(t, body, `<span class="method post">POST</span><span class="path">/api/thing/{id}</span> &mdash; create a thing`)
+	assert.Contains(t, body, "<code>typedResponse</code>")
+	assert.Contains(t, body, "<td>X-Tenant <span class=\"required\">*</span></td><td>header</td>")
+	assert.Contains(t, body, `<span class="path">openAPINode</span>`)
+	assert.Less(t, strings.Index(body, "/api/thing/{id}"), strings.Index(body, "/ping"))
+}
+
+func TestNewTyped_Operation(t *testing.T) {
+	operation := &Operation{Summary: "typed"}
+	wrapper := NewTyped(func(_ context.Context, request typedRequest) ([]typedResponse, error) {
+		return nil, nil
+	}, Options{Operation: operation})
+	require.NotNil(t, wrapper.options.Operation)
+	assert.Equal(t, "typed", wrapper.options.Operation.Summary)
+	assert.IsType(t, &typedRequest{}, wrapper.options.Operation.Request)
+	assert.IsType(t, &[]typedResponse{}, wrapper.options.Operation.Response)
+	assert.Nil(t, operation.Request, "caller's operation unchanged")
+	assert.Nil(t, NewTyped(func(_ context.Context, request typedRequest) (int, error) {
+		return 0, nil
+	}, Options{}).options.Operation)
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	path, params := openAPIPath("/a/:b/c/*d")
+	assert.Equal(t, "/a/{b}/c/{d}", path)
+	assert.Equal(t, []string{"b", "d"}, params)
+	path, params = openAPIPath("/")
+	assert.Equal(t, "/", path)
+	assert.Empty(t, params)
+}
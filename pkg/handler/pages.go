@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Page template file names.
+const (
+	pageLayout   = "layout.html"
+	pageCentered = "centered.html"
+	pageLinks    = "links.html"
+	pageRoutes   = "routes.html"
+	pageOpenAPI  = "openapi.html"
+	pageInfo     = "info.html"
+)
+
+// pageNames lists the page templates, each of which is combined with the layout template.
+var pageNames = []string{pageCentered, pageLinks, pageRoutes, pageOpenAPI, pageInfo}
+
+//go:embed templates/*.html
+var embeddedTemplates embed.FS
+
+// pageFuncs are the functions available in page templates.
+var pageFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"linkable": func(route RouteInfo) bool {
+		return route.Method == http.MethodGet && !strings.ContainsAny(route.Path, ":*")
+	},
+}
+
+var (
+	pageTemplates map[string]*template.Template
+	pageLock      sync.RWMutex
+)
+
+func init() {
+	templates, err := parsePageTemplates(nil)
+	if err != nil {
+		panic("parse embedded page templates: " + err.Error())
+	}
+	pageTemplates = templates
+}
+
+// DefaultPageTemplates returns the embedded page templates
+// for use as a starting point for custom templates.
+func DefaultPageTemplates() fs.FS {
+	templates, _ := fs.Sub(embeddedTemplates, "templates")
+	return templates
+}
+
+// SetPageTemplates overrides the HTML page templates used by Links, RouteIndex, Info, the OpenAPI viewer,
+// and the HTML centered text and error pages, for example to brand them.
+//
+// Files in the specified file system with the same names as the default templates
+// (layout.html, centered.html, links.html, routes.html, openapi.html, and info.html) replace the defaults.
+// The layout template defines "layout" which each page template invokes after defining "content"
+// (and optionally "style"); see DefaultPageTemplates.
+// Templates may use the functions lower and linkable (for RouteInfo).
+//
+// A nil file system restores the default templates.
+// The current templates are not changed if there is an error.
+func SetPageTemplates(fsys fs.FS) error {
+	templates, err := parsePageTemplates(fsys)
+	if err != nil {
+		return err
+	}
+	pageLock.Lock()
+	defer pageLock.Unlock()
+	pageTemplates = templates
+	return nil
+}
+
+// parsePageTemplates parses each page template with the layout template,
+// using files from the override file system where they exist.
+func parsePageTemplates(override fs.FS) (map[string]*template.Template, error) {
+	source := func(name string) fs.FS {
+		if override != nil {
+			if _, err := fs.Stat(override, name); err == nil {
+				return override
+			}
+		}
+		return DefaultPageTemplates()
+	}
+	layout, err := template.New(pageLayout).Funcs(pageFuncs).ParseFS(source(pageLayout), pageLayout)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", pageLayout, err)
+	}
+	templates := make(map[string]*template.Template, len(pageNames))
+	for _, name := range pageNames {
+		page, err := layout.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("clone layout for %s: %w", name, err)
+		}
+		if templates[name], err = page.ParseFS(source(name), name); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+	}
+	return templates, nil
+}
+
+// pageData is the data for all page templates.
+type pageData struct {
+	Title      string
+	StyleSheet template.CSS
+	// Centered text page.
+	Lines []string
+	// Links page.
+	Links []LinkDef
+	// Routes page.
+	Groups []RouteGroup
+	// OpenAPI viewer page.
+	API *openAPIView
+	// Info page.
+	Fields []pageField
+}
+
+// pageField is a single name/value pair on a page.
+type pageField struct {
+	Name  string
+	Value string
+}
+
+// writePage executes the named page template and writes it with the specified status code.
+// The page is rendered to a buffer first so that template errors result in
+// an Internal Server Error instead of a partial page.
+func writePage(writer http.ResponseWriter, code int, name string, data *pageData) {
+	pageLock.RLock()
+	page, found := pageTemplates[name]
+	pageLock.RUnlock()
+	var buffer bytes.Buffer
+	err := errors.New("no such page")
+	if found {
+		err = page.ExecuteTemplate(&buffer, name, data)
+	}
+	if err != nil {
+		log.Logger.Error().Err(err).Str("page", name).Msg("Rendering page")
+		ErrorResult(writer, http.StatusInternalServerError, "Error rendering page")
+		return
+	}
+	writer.Header().Set(hdrContentType, hdrContentTypeHTMLValue)
+	writer.Header().Set(hdrContentTypeOptions, hdrContentTypeOptionsValue)
+	writer.WriteHeader(code)
+	if _, err := writer.Write(buffer.Bytes()); err != nil {
+		log.Logger.Error().Err(err).Str("page", name).Msg("Writing page")
+	}
+}
+
+// writeCenteredText writes an HTML page with the specified title and
+// the specified text centered on the page.
+// Newlines in the text are rendered as line breaks.
+func writeCenteredText(writer http.ResponseWriter, title, text string) {
+	writeCenteredTextCode(writer, http.StatusOK, title, text)
+}
+
+// writeCenteredTextCode writes an HTML page with centered text and the specified status code.
+func writeCenteredTextCode(writer http.ResponseWriter, code int, title, text string) {
+	writePage(writer, code, pageCentered, &pageData{Title: title, Lines: strings.Split(text, "\n")})
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCenteredText(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeCenteredText(rec, "Title <b>", "first\nsecond <script>alert(1)</script>")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeHTMLValue, rec.Header().Get(hdrContentType))
+	assert.Equal(t, hdrContentTypeOptionsValue, rec.Header().Get(hdrContentTypeOptions))
+	body := rec.Body.String()
+	assert.Regexp(t, `^<!DOCTYPE html>`, body)
+	assert.Contains(t, body, `<meta charset="utf-8">`)
+	assert.Contains(t, body, "<title>Title &lt;b&gt;</title>")
+	assert.Contains(t, body, "first<br/>second &lt;script&gt;")
+	assert.NotContains(t, body, "<script>")
+}
+
+func TestLinks_Escaped(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	Links(ctx, "table.links { color: red; }",
+		LinkDef{Path: "javascript:alert(1)", Name: "<b>Bad</b>", Description: `"quoted"`},
+		LinkDef{Path: "/ping?a=1&b=2", Name: "Ping", Description: "server existence"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeHTMLValue, rec.Header().Get(hdrContentType))
+	body := rec.Body.String()
+	assert.Contains(t, body, "table.links { color: red; }")
+	assert.NotContains(t, body, "javascript:")
+	assert.Contains(t, body, "&lt;b&gt;Bad&lt;/b&gt;")
+	assert.Contains(t, body, "&#34;quoted&#34;")
+	assert.Contains(t, body, `<a href="/ping?a=1&amp;b=2">Ping</a>`)
+}
+
+func TestSetPageTemplates(t *testing.T) {
+	defer func() { require.NoError(t, SetPageTemplates(nil)) }()
+
+	require.NoError(t, SetPageTemplates(fstest.MapFS{
+		pageLayout: &fstest.MapFile{Data: []byte(
+			`{{define "layout"}}<!DOCTYPE html><title>ACME: {{.Title}}</title>{{template "content" .}}{{end}}`)},
+	}))
+	rec := httptest.NewRecorder()
+	writeCenteredText(rec, "Branded", "Hello")
+	assert.Contains(t, rec.Body.String(), "<title>ACME: Branded</title>")
+	assert.Contains(t, rec.Body.String(), `<div class="centered">Hello</div>`)
+
+	require.NoError(t, SetPageTemplates(fstest.MapFS{
+		pageCentered: &fstest.MapFile{Data: []byte(
+			`{{template "layout" .}}{{define "content"}}<p>{{range .Lines}}{{.}}{{end}}</p>{{end}}`)},
+	}))
+	rec = httptest.NewRecorder()
+	writeCenteredText(rec, "Custom", "<Hello>")
+	assert.Contains(t, rec.Body.String(), "<title>Custom</title>")
+	assert.Contains(t, rec.Body.String(), "<p>&lt;Hello&gt;</p>")
+
+	// Bad templates leave the current templates in place.
+	assert.Error(t, SetPageTemplates(fstest.MapFS{pageLinks: &fstest.MapFile{Data: []byte(`{{if}}`)}}))
+	rec = httptest.NewRecorder()
+	writeCenteredText(rec, "Custom", "Hello")
+	assert.Contains(t, rec.Body.String(), "<p>Hello</p>")
+
+	// Execution errors result in an Internal Server Error.
+	require.NoError(t, SetPageTemplates(fstest.MapFS{
+		pageCentered: &fstest.MapFile{Data: []byte(`{{template "layout" .}}{{define "content"}}{{.Missing}}{{end}}`)},
+	}))
+	rec = httptest.NewRecorder()
+	writeCenteredText(rec, "Broken", "Hello")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "Broken")
+}
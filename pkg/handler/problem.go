@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	hdrAccept                      = "Accept"
+	hdrContentTypeHTMLValue        = "text/html; charset=utf-8"
+	hdrContentTypeProblemJSONValue = "application/problem+json"
+)
+
+// Problem represents an RFC 7807 problem details object.
+// Extension members are serialized at the top level of the JSON object
+// but cannot replace the standard members.
+type Problem struct {
+	// URI reference that identifies the problem type, "about:blank" if empty.
+	Type string
+	// Short, human-readable summary of the problem type.
+	Title string
+	// HTTP status code.
+	Status int
+	// Human-readable explanation specific to this occurrence of the problem.
+	Detail string
+	// URI reference that identifies the specific occurrence of the problem.
+	Instance string
+	// Additional members of the problem details object.
+	Extensions map[string]any
+}
+
+// NewProblem returns a Problem for the specified status code with the standard status text as title.
+func NewProblem(code int, detail string) *Problem {
+	return &Problem{
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: detail,
+	}
+}
+
+// MarshalJSON implements json.Marshaler to include extension members at the top level.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	standard := map[string]any{
+		"type":     p.Type,
+		"title":    p.Title,
+		"status":   p.Status,
+		"detail":   p.Detail,
+		"instance": p.Instance,
+	}
+	for key, value := range standard {
+		delete(members, key)
+		if value != "" && value != 0 {
+			members[key] = value
+		}
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON implements json.Unmarshaler to collect extension members.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	*p = Problem{}
+	fields := map[string]any{
+		"type":     &p.Type,
+		"title":    &p.Title,
+		"status":   &p.Status,
+		"detail":   &p.Detail,
+		"instance": &p.Instance,
+	}
+	for key, raw := range members {
+		if field, found := fields[key]; found {
+			if err := json.Unmarshal(raw, field); err != nil {
+				return fmt.Errorf("problem member %s: %w", key, err)
+			}
+		} else {
+			var value any
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("problem extension %s: %w", key, err)
+			}
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]any)
+			}
+			p.Extensions[key] = value
+		}
+	}
+	return nil
+}
+
+// ProblemResult returns the specified problem as an application/problem+json message.
+func ProblemResult(writer http.ResponseWriter, problem *Problem) {
+	code := problem.Status
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	bytes, err := json.Marshal(problem)
+	if err != nil {
+		// Extension members may not be marshalable, drop them.
+		bytes, _ = json.Marshal(&Problem{Type: problem.Type, Title: problem.Title, Status: code, Detail: problem.Detail})
+	}
+	writer.Header().Set(hdrContentType, hdrContentTypeProblemJSONValue)
+	writer.Header().Set(hdrContentTypeOptions, hdrContentTypeOptionsValue)
+	writer.WriteHeader(code)
+	_, _ = writer.Write(bytes)
+}
+
+// NegotiatedErrorResult returns an error result in the format requested by
+// the Accept header of the request: plain text (as ErrorResult),
+// HTML, or application/problem+json. Plain text is the default.
+// Any text is joined into the problem detail.
+func NegotiatedErrorResult(writer http.ResponseWriter, request *http.Request, code int, text ...string) {
+	NegotiatedProblemResult(writer, request, NewProblem(code, strings.Join(text, "\n")))
+}
+
+// NegotiatedProblemResult returns the specified problem in the format requested by
+// the Accept header of the request: plain text, HTML, or application/problem+json.
+// Plain text is the default.
+func NegotiatedProblemResult(writer http.ResponseWriter, request *http.Request, problem *Problem) {
+	code := problem.Status
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	var accept string
+	if request != nil {
+		accept = request.Header.Get(hdrAccept)
+	}
+	switch negotiate(accept, errorMediaTypes) {
+	case mediaProblem, mediaJSON:
+		ProblemResult(writer, problem)
+	case mediaHTML:
+		title := problem.Title
+		if title == "" {
+			title = http.StatusText(code)
+		}
+		writeCenteredTextCode(writer, code, title, problem.Detail)
+	default:
+		var text []string
+		if problem.Detail != "" {
+			text = strings.Split(problem.Detail, "\n")
+		}
+		ErrorResult(writer, code, text...)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Media types for error content negotiation.
+const (
+	mediaText    = "text/plain"
+	mediaHTML    = "text/html"
+	mediaJSON    = "application/json"
+	mediaProblem = "application/problem+json"
+)
+
+// errorMediaTypes lists the media types for errors in order of server preference.
+var errorMediaTypes = []string{mediaText, mediaProblem, mediaJSON, mediaHTML}
+
+// negotiate returns the offered media type that best matches the Accept header.
+// The first offer is returned if the Accept header is empty or nothing matches.
+func negotiate(accept string, offers []string) string {
+	if accept == "" {
+		return offers[0]
+	}
+	type accepted struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []accepted
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, accepted{mediaType: mediaType, quality: quality})
+	}
+	// More specific ranges take precedence over wildcards.
+	specificity := func(mediaType string) int {
+		switch {
+		case mediaType == "*/*":
+			return 0
+		case strings.HasSuffix(mediaType, "/*"):
+			return 1
+		default:
+			return 2
+		}
+	}
+	best, bestQuality := offers[0], 0.0
+	for _, offer := range offers {
+		// Find the most specific matching range for the offer.
+		quality, matched := 0.0, -1
+		for _, r := range ranges {
+			prefix, isWild := strings.CutSuffix(r.mediaType, "*")
+			if r.mediaType == offer || r.mediaType == "*/*" || (isWild && strings.HasPrefix(offer, prefix)) {
+				if s := specificity(r.mediaType); s > matched {
+					quality, matched = r.quality, s
+				}
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
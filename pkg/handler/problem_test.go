@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblem_JSON(t *testing.T) {
+	problem := &Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Detail:     "Your current balance is 30, but that costs 50.",
+		Instance:   "/account/12345/msgs/abc",
+		Extensions: map[string]any{"balance": float64(30), "status": "ignored"},
+	}
+	bytes, err := json.Marshal(problem)
+	require.NoError(t, err)
+	var members map[string]any
+	require.NoError(t, json.Unmarshal(bytes, &members))
+	assert.Equal(t, float64(http.StatusForbidden), members["status"])
+	assert.Equal(t, float64(30), members["balance"])
+	var received Problem
+	require.NoError(t, json.Unmarshal(bytes, &received))
+	delete(problem.Extensions, "status")
+	assert.Equal(t, *problem, received)
+}
+
+func TestProblemResult(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ProblemResult(rec, NewProblem(http.StatusNotFound, "No such thing"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, hdrContentTypeProblemJSONValue, rec.Header().Get(hdrContentType))
+	assert.JSONEq(t, `{"title":"Not Found","status":404,"detail":"No such thing"}`, rec.Body.String())
+}
+
+func TestJSONResult_Problem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JSONResult(rec, map[string]any{"bad": make(chan int)})
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, hdrContentTypeProblemJSONValue, rec.Header().Get(hdrContentType))
+}
+
+func TestNegotiatedErrorResult(t *testing.T) {
+	for accept, contentType := range map[string]string{
+		"":                                      hdrContentTypeTextValue,
+		"*/*":                                   hdrContentTypeTextValue,
+		"application/json":                      hdrContentTypeProblemJSONValue,
+		"application/problem+json":              hdrContentTypeProblemJSONValue,
+		"application/*;q=0.5, text/plain;q=0.4": hdrContentTypeProblemJSONValue,
+		"text/html,application/xhtml+xml,*/*;q=0.8": hdrContentTypeHTMLValue,
+		"image/png": hdrContentTypeTextValue,
+	} {
+		rec := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if accept != "" {
+			request.Header.Set(hdrAccept, accept)
+		}
+		NegotiatedErrorResult(rec, request, http.StatusBadRequest, "Bad <doggy>!")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, accept)
+		assert.Equal(t, contentType, rec.Header().Get(hdrContentType), accept)
+		if contentType == hdrContentTypeHTMLValue {
+			assert.Contains(t, rec.Body.String(), "Bad &lt;doggy&gt;!")
+		} else {
+			assert.Contains(t, rec.Body.String(), "doggy")
+		}
+	}
+}
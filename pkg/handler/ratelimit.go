@@ -0,0 +1,310 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Rate limit headers.
+const (
+	hdrRateLimitLimit     = "RateLimit-Limit"
+	hdrRateLimitRemaining = "RateLimit-Remaining"
+	hdrRateLimitReset     = "RateLimit-Reset"
+	hdrRateLimitPolicy    = "RateLimit-Policy"
+	hdrRetryAfter         = "Retry-After"
+	rateLimitedText       = "Rate limit exceeded"
+)
+
+// ErrRateLimited is added to the gin.Context errors when a request is rejected by a RateLimiter.
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitAlgorithm specifies how requests are counted.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts of up to Limit requests
+	// and refills at a steady rate of Limit requests per Period.
+	TokenBucket RateLimitAlgorithm = iota
+
+	// SlidingWindow allows at most Limit requests in any interval of length Period.
+	SlidingWindow
+)
+
+// String returns the name of the algorithm.
+func (a RateLimitAlgorithm) String() string {
+	switch a {
+	case TokenBucket:
+		return "token-bucket"
+	case SlidingWindow:
+		return "sliding-window"
+	default:
+		return "unknown"
+	}
+}
+
+// RateLimit defines the number of requests allowed per period.
+type RateLimit struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Period    time.Duration
+}
+
+// RateLimitResult is the result of counting a request against a RateLimit.
+type RateLimitResult struct {
+	// True if the request is allowed.
+	Allowed bool
+	// Requests remaining before the limit is reached.
+	Remaining int
+	// Time until the full quota is available again.
+	Reset time.Duration
+	// Time until the next request will be allowed, zero if Allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore holds rate limit state for keys.
+// Implementations must count requests atomically
+// so that a store may be shared by multiple servers.
+type RateLimitStore interface {
+	// Take counts a request for the key against the limit at the specified time.
+	Take(ctxt context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitKeyFunc returns the key for counting a request.
+type RateLimitKeyFunc func(ctx *gin.Context) string
+
+// RateLimitByIP counts requests by the IP address of the connection (see gin.Context.RemoteIP).
+// Headers such as X-Forwarded-For are ignored since clients can send a new address with every request.
+// Behind a reverse proxy all requests share the address of the proxy,
+// use RateLimitByClientIP instead.
+func RateLimitByIP(ctx *gin.Context) string {
+	return ctx.RemoteIP()
+}
+
+// RateLimitByClientIP counts requests by client IP address (see gin.Context.ClientIP),
+// which is taken from headers such as X-Forwarded-For when the request comes from a trusted proxy.
+// Gin trusts all proxies by default so the headers can be spoofed by any client.
+// Call gin.Engine.SetTrustedProxies() with the addresses of the reverse proxies before using this key function.
+func RateLimitByClientIP(ctx *gin.Context) string {
+	return ctx.ClientIP()
+}
+
+// RateLimitByHeader returns a RateLimitKeyFunc that counts requests
+// by the value of the specified header (e.g. an API key).
+// Requests without the header or with a value rejected by the valid function
+// are counted by IP address (see RateLimitByIP).
+//
+// Clients can send a new header value with every request,
+// which would bypass the limit and add a MemoryRateLimitStore entry for each value.
+// Specify a valid function (e.g. a lookup of known API keys) unless the header
+// has already been authenticated by earlier middleware, in which case valid may be nil.
+func RateLimitByHeader(name string, valid func(value string) bool) RateLimitKeyFunc {
+	return func(ctx *gin.Context) string {
+		if value := ctx.GetHeader(name); value != "" && (valid == nil || valid(value)) {
+			return name + ":" + value
+		}
+		return RateLimitByIP(ctx)
+	}
+}
+
+// RateLimiterOptions configure a RateLimiter.
+type RateLimiterOptions struct {
+	RateLimit
+
+	// Function that returns the key for counting a request, defaults to RateLimitByIP.
+	Key RateLimitKeyFunc
+
+	// Store for rate limit state, defaults to a new MemoryRateLimitStore.
+	Store RateLimitStore
+}
+
+// RateLimiter rejects requests that exceed a RateLimit with 429 Too Many Requests.
+// Use it as gin middleware or convert it via MiddlewareFromGin for the Wrapper Options.
+//
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, and RateLimit-Policy headers
+// are set on all responses and Retry-After is set on rejected responses.
+// If the Store returns an error the request is logged and allowed.
+type RateLimiter struct {
+	options RateLimiterOptions
+	policy  string
+}
+
+// NewRateLimiter returns a RateLimiter with the specified options.
+// Returns an error if the Limit or Period is not positive or the Algorithm is unknown,
+// since every request would otherwise be allowed after a store error.
+func NewRateLimiter(options RateLimiterOptions) (*RateLimiter, error) {
+	if options.Limit <= 0 || options.Period <= 0 {
+		return nil, fmt.Errorf("invalid rate limit %d per %s", options.Limit, options.Period)
+	}
+	if options.Algorithm != TokenBucket && options.Algorithm != SlidingWindow {
+		return nil, fmt.Errorf("unknown rate limit algorithm %d", options.Algorithm)
+	}
+	if options.Key == nil {
+		options.Key = RateLimitByIP
+	}
+	if options.Store == nil {
+		options.Store = NewMemoryRateLimitStore()
+	}
+	return &RateLimiter{
+		options: options,
+		policy:  fmt.Sprintf("%d;w=%d", options.Limit, int(math.Ceil(options.Period.Seconds()))),
+	}, nil
+}
+
+// Middleware returns a gin middleware function that applies the rate limit.
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !rl.Allow(ctx) {
+			ctx.Abort()
+		}
+	}
+}
+
+// Allow counts the request and sets the rate limit headers.
+// Returns false if the request exceeds the limit,
+// in which case a 429 Too Many Requests response has been written.
+func (rl *RateLimiter) Allow(ctx *gin.Context) bool {
+	result, err := rl.options.Store.Take(ctx.Request.Context(), rl.options.Key(ctx), rl.options.RateLimit, time.Now())
+	if err != nil {
+		log.Error().Err(err).Str("path", ctx.Request.URL.Path).Msg("Rate limit store")
+		return true
+	}
+
+	header := ctx.Writer.Header()
+	header.Set(hdrRateLimitLimit, strconv.Itoa(rl.options.Limit))
+	header.Set(hdrRateLimitRemaining, strconv.Itoa(result.Remaining))
+	header.Set(hdrRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+	header.Set(hdrRateLimitPolicy, rl.policy)
+	if result.Allowed {
+		return true
+	}
+
+	header.Set(hdrRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+	_ = ctx.Error(ErrRateLimited).SetType(gin.ErrorTypePublic)
+	NegotiatedErrorResult(ctx.Writer, ctx.Request, http.StatusTooManyRequests, rateLimitedText)
+	return false
+}
+
+// ceilSeconds returns the duration in whole seconds, rounded up.
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// Make sure the MemoryRateLimitStore struct implements RateLimitStore.
+var _ = RateLimitStore(&MemoryRateLimitStore{})
+
+// rateLimitSweepInterval is the minimum interval between removals of idle keys.
+const rateLimitSweepInterval = time.Minute
+
+// MemoryRateLimitStore is an in-memory RateLimitStore for a single server.
+// Idle keys are removed periodically.
+type MemoryRateLimitStore struct {
+	states    map[string]*rateLimitState
+	lastSweep time.Time
+	lock      sync.Mutex
+}
+
+// rateLimitState holds the state for a single key.
+type rateLimitState struct {
+	algorithm RateLimitAlgorithm
+	// Token bucket.
+	tokens  float64
+	updated time.Time
+	// Sliding window request times, oldest first.
+	requests []time.Time
+	// Time after which the state is the same as a new state.
+	idle time.Time
+}
+
+// NewMemoryRateLimitStore returns an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{states: make(map[string]*rateLimitState)}
+}
+
+// Take counts a request for the key against the limit at the specified time.
+func (ms *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return RateLimitResult{}, fmt.Errorf("invalid rate limit %d per %s", limit.Limit, limit.Period)
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	if now.Sub(ms.lastSweep) > rateLimitSweepInterval {
+		for k, state := range ms.states {
+			if now.After(state.idle) {
+				delete(ms.states, k)
+			}
+		}
+		ms.lastSweep = now
+	}
+
+	state, found := ms.states[key]
+	if !found || state.algorithm != limit.Algorithm {
+		state = &rateLimitState{algorithm: limit.Algorithm, tokens: float64(limit.Limit), updated: now}
+		ms.states[key] = state
+	}
+	switch limit.Algorithm {
+	case TokenBucket:
+		return state.takeToken(limit, now), nil
+	case SlidingWindow:
+		return state.takeWindow(limit, now), nil
+	default:
+		return RateLimitResult{}, fmt.Errorf("unknown rate limit algorithm %d", limit.Algorithm)
+	}
+}
+
+// takeToken refills the token bucket and takes a token if available.
+func (s *rateLimitState) takeToken(limit RateLimit, now time.Time) RateLimitResult {
+	rate := float64(limit.Limit) / limit.Period.Seconds()
+	if elapsed := now.Sub(s.updated).Seconds(); elapsed > 0 {
+		s.tokens = math.Min(float64(limit.Limit), s.tokens+elapsed*rate)
+		s.updated = now
+	}
+	result := RateLimitResult{}
+	if s.tokens >= 1 {
+		s.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsDuration((1 - s.tokens) / rate)
+	}
+	result.Remaining = int(s.tokens)
+	result.Reset = secondsDuration((float64(limit.Limit) - s.tokens) / rate)
+	s.idle = now.Add(result.Reset)
+	return result
+}
+
+// takeWindow drops requests outside the window and counts the request if there is room.
+func (s *rateLimitState) takeWindow(limit RateLimit, now time.Time) RateLimitResult {
+	start := now.Add(-limit.Period)
+	expired := 0
+	for expired < len(s.requests) && !s.requests[expired].After(start) {
+		expired++
+	}
+	s.requests = s.requests[expired:]
+	result := RateLimitResult{}
+	if len(s.requests) < limit.Limit {
+		s.requests = append(s.requests, now)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = s.requests[0].Add(limit.Period).Sub(now)
+	}
+	result.Remaining = limit.Limit - len(s.requests)
+	result.Reset = s.requests[len(s.requests)-1].Add(limit.Period).Sub(now)
+	s.idle = now.Add(result.Reset)
+	return result
+}
+
+// secondsDuration converts floating point seconds to a time.Duration.
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
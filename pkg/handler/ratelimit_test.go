@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimitStore_TokenBucket(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Algorithm: TokenBucket, Limit: 2, Period: 2 * time.Second}
+	now := time.Now()
+	result, err := store.Take(context.Background(), "key", limit, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, time.Second, result.Reset)
+	result, err = store.Take(context.Background(), "key", limit, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, err = store.Take(context.Background(), "key", limit, now)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 2*time.Second, result.Reset)
+
+	// Other keys are independent.
+	result, err = store.Take(context.Background(), "other", limit, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Refill one token.
+	result, err = store.Take(context.Background(), "key", limit, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryRateLimitStore_SlidingWindow(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Algorithm: SlidingWindow, Limit: 2, Period: time.Minute}
+	now := time.Now()
+	for i, offset := range []time.Duration{0, 10 * time.Second} {
+		result, err := store.Take(context.Background(), "key", limit, now.Add(offset))
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+	result, err := store.Take(context.Background(), "key", limit, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+	assert.Equal(t, 40*time.Second, result.Reset)
+
+	// First request has left the window.
+	result, err = store.Take(context.Background(), "key", limit, now.Add(time.Minute+time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemoryRateLimitStore_Invalid(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	_, err := store.Take(context.Background(), "key", RateLimit{Limit: 0, Period: time.Second}, time.Now())
+	assert.Error(t, err)
+	_, err = store.Take(context.Background(), "key", RateLimit{Algorithm: 99, Limit: 1, Period: time.Second}, time.Now())
+	assert.Error(t, err)
+}
+
+func TestMemoryRateLimitStore_Sweep(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Algorithm: TokenBucket, Limit: 1, Period: time.Second}
+	now := time.Now()
+	_, err := store.Take(context.Background(), "old", limit, now)
+	require.NoError(t, err)
+	_, err = store.Take(context.Background(), "new", limit, now.Add(2*rateLimitSweepInterval))
+	require.NoError(t, err)
+	assert.Len(t, store.states, 1)
+	assert.Contains(t, store.states, "new")
+}
+
+func testRateLimitRequest(router *gin.Engine, header map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	for key, value := range header {
+		request.Header.Set(key, value)
+	}
+	router.ServeHTTP(rec, request)
+	return rec
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOptions{
+		RateLimit: RateLimit{Algorithm: SlidingWindow, Limit: 1, Period: time.Minute},
+		Key: RateLimitByHeader("X-API-Key", func(value string) bool {
+			return value == "alpha" || value == "bravo"
+		}),
+	})
+	require.NoError(t, err)
+	router := gin.New()
+	router.GET("/limited", limiter.Middleware(), Ping)
+
+	rec := testRateLimitRequest(router, map[string]string{"X-API-Key": "alpha"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get(hdrRateLimitLimit))
+	assert.Equal(t, "0", rec.Header().Get(hdrRateLimitRemaining))
+	assert.Equal(t, "60", rec.Header().Get(hdrRateLimitReset))
+	assert.Equal(t, "1;w=60", rec.Header().Get(hdrRateLimitPolicy))
+	assert.Empty(t, rec.Header().Get(hdrRetryAfter))
+
+	rec = testRateLimitRequest(router, map[string]string{"X-API-Key": "alpha", hdrAccept: hdrContentTypeProblemJSONValue})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, hdrContentTypeProblemJSONValue, rec.Header().Get(hdrContentType))
+	assert.Contains(t, rec.Body.String(), rateLimitedText)
+	assert.NotEmpty(t, rec.Header().Get(hdrRetryAfter))
+
+	// Different API key and no API key (client IP).
+	rec = testRateLimitRequest(router, map[string]string{"X-API-Key": "bravo"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = testRateLimitRequest(router, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = testRateLimitRequest(router, nil)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Unknown API keys are counted by client IP.
+	rec = testRateLimitRequest(router, map[string]string{"X-API-Key": "charlie"})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	// Forwarding headers don't change the client IP.
+	rec = testRateLimitRequest(router, map[string]string{"X-Forwarded-For": "10.9.8.7", "X-Real-IP": "10.9.8.6"})
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestRateLimitByClientIP(t *testing.T) {
+	key := func(trusted []string) string {
+		router := gin.New()
+		require.NoError(t, router.SetTrustedProxies(trusted))
+		var key string
+		router.GET("/key", func(ctx *gin.Context) {
+			key = RateLimitByClientIP(ctx)
+			assert.Equal(t, "192.0.2.1", RateLimitByIP(ctx))
+		})
+		testRequest := httptest.NewRequest(http.MethodGet, "/key", nil)
+		testRequest.Header.Set("X-Forwarded-For", "10.9.8.7")
+		router.ServeHTTP(httptest.NewRecorder(), testRequest)
+		return key
+	}
+	assert.Equal(t, "10.9.8.7", key([]string{"192.0.2.1"}))
+	assert.Equal(t, "192.0.2.1", key(nil))
+}
+
+func TestNewRateLimiter_Invalid(t *testing.T) {
+	for _, limit := range []RateLimit{
+		{Limit: 0, Period: time.Second},
+		{Limit: -1, Period: time.Second},
+		{Limit: 1},
+		{Limit: 1, Period: -time.Second},
+		{Algorithm: RateLimitAlgorithm(7), Limit: 1, Period: time.Second},
+	} {
+		_, err := NewRateLimiter(RateLimiterOptions{RateLimit: limit})
+		assert.Error(t, err, limit)
+	}
+}
+
+// failingStore always returns an error.
+type failingStore struct{}
+
+func (fs failingStore) Take(_ context.Context, _ string, _ RateLimit, _ time.Time) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store offline")
+}
+
+func TestRateLimiter_StoreError(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimiterOptions{
+		RateLimit: RateLimit{Limit: 1, Period: time.Second},
+		Store:     failingStore{},
+	})
+	require.NoError(t, err)
+	hdlrFunc := NewWrapped(&wrapped{title: "Greetings", text: "Hello, world!"}, Options{
+		Middleware: []Middleware{MiddlewareFromGin(limiter.Middleware())},
+	}).HandlerFunc()
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		hdlrFunc(ctx)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(hdrRateLimitLimit))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -27,15 +29,17 @@ func ErrorResult(writer http.ResponseWriter, code int, text ...string) {
 	}
 }
 
-// JSONResult returns a JSON message representing the specified object
+// JSONResult returns a JSON message representing the specified object.
+// If the object can't be marshaled an application/problem+json error is returned
+// so that API clients always receive JSON.
 func JSONResult(writer http.ResponseWriter, object any) {
 	if bytes, err := json.Marshal(object); err != nil {
-		ErrorResult(writer, http.StatusInternalServerError, err.Error())
+		ProblemResult(writer, NewProblem(http.StatusInternalServerError, err.Error()))
 	} else {
 		writer.Header().Set(hdrContentType, hdrContentTypeJSONValue)
 		writer.WriteHeader(http.StatusOK)
 		if _, err = writer.Write(bytes); err != nil {
-			ErrorResult(writer, http.StatusInternalServerError, err.Error())
+			log.Logger.Error().Err(err).Msg("Writing JSON result")
 		}
 	}
 }
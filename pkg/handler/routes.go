@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteIndexStyleSheet is the default style sheet for the RouteIndex HTML page.
+// The style sheet is not escaped and must be trusted.
+const RouteIndexStyleSheet = `
+    table.routes {
+      border-collapse: collapse;
+      margin-left: auto;
+      margin-right: auto;
+    }
+
+    table.routes th.group {
+      text-align: left;
+      padding-top: 1em;
+    }
+
+    table.routes td.description {
+      font-style: italic;
+      padding-left: 1em;
+    }
+
+    span.method {
+      display: inline-block;
+      min-width: 4em;
+      padding: 0 0.25em;
+      border-radius: 0.25em;
+      font-family: monospace;
+      text-align: center;
+      color: white;
+      background-color: gray;
+    }
+
+    span.method.get { background-color: green; }
+    span.method.post { background-color: darkorange; }
+    span.method.put { background-color: blue; }
+    span.method.patch { background-color: purple; }
+    span.method.delete { background-color: darkred; }
+`
+
+// RouteInfo describes a single route in a RouteIndex.
+type RouteInfo struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Handler     string `json:"handler"`
+}
+
+// RouteGroup is a group of routes with a common path prefix.
+type RouteGroup struct {
+	Prefix string      `json:"prefix"`
+	Routes []RouteInfo `json:"routes"`
+}
+
+// RouteIndex serves an index of the routes configured in a gin.Engine.
+// The index is built from gin.Engine.Routes() when it is served
+// so routes never have to be listed by hand.
+// Descriptions may be registered alongside routes via Handle or added via Describe.
+//
+// The index is rendered as HTML by default and as JSON if the request Accept header
+// prefers application/json or the request has the query parameter format=json.
+// Routes are grouped by the first segments of their paths (see GroupDepth)
+// and GET routes without path parameters are shown as links.
+type RouteIndex struct {
+	engine       *gin.Engine
+	descriptions map[string]string
+	groupDepth   int
+	styleSheet   string
+	lock         sync.RWMutex
+}
+
+// RouteRegistrar is a gin.IRoutes with a base path, such as a *gin.Engine or *gin.RouterGroup.
+type RouteRegistrar interface {
+	gin.IRoutes
+	BasePath() string
+}
+
+// NewRouteIndex returns a RouteIndex for the specified engine.
+func NewRouteIndex(engine *gin.Engine) *RouteIndex {
+	return &RouteIndex{
+		engine:       engine,
+		descriptions: make(map[string]string),
+		groupDepth:   1,
+		styleSheet:   RouteIndexStyleSheet,
+	}
+}
+
+// GroupDepth sets the number of path segments used to group routes, zero for no grouping.
+// The default is one (e.g. "/api").
+// Returns the RouteIndex so that calls can be chained.
+func (ri *RouteIndex) GroupDepth(depth int) *RouteIndex {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	ri.groupDepth = depth
+	return ri
+}
+
+// StyleSheet sets the style sheet for the HTML page, the default is RouteIndexStyleSheet.
+// Returns the RouteIndex so that calls can be chained.
+func (ri *RouteIndex) StyleSheet(styleSheet string) *RouteIndex {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	ri.styleSheet = styleSheet
+	return ri
+}
+
+// Describe adds a description for the route with the specified method and full path.
+// Returns the RouteIndex so that calls can be chained.
+func (ri *RouteIndex) Describe(method, fullPath, description string) *RouteIndex {
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	ri.descriptions[routeKey(method, fullPath)] = description
+	return ri
+}
+
+// Handle registers the handlers for the method and relative path with the router
+// and adds the description for the route.
+func (ri *RouteIndex) Handle(router RouteRegistrar, method, relativePath, description string, handlers ...gin.HandlerFunc) {
+	router.Handle(method, relativePath, handlers...)
+	ri.Describe(method, joinRoutePaths(router.BasePath(), relativePath), description)
+}
+
+// GET registers the handlers for GET requests to the relative path with the router
+// and adds the description for the route.
+func (ri *RouteIndex) GET(router RouteRegistrar, relativePath, description string, handlers ...gin.HandlerFunc) {
+	ri.Handle(router, http.MethodGet, relativePath, description, handlers...)
+}
+
+// Groups returns the current routes of the engine grouped by path prefix.
+// Groups are sorted by prefix and routes within a group by path and then method.
+func (ri *RouteIndex) Groups() []RouteGroup {
+	ri.lock.RLock()
+	defer ri.lock.RUnlock()
+	byPrefix := make(map[string][]RouteInfo)
+	for _, route := range ri.engine.Routes() {
+		prefix := routePrefix(route.Path, ri.groupDepth)
+		byPrefix[prefix] = append(byPrefix[prefix], RouteInfo{
+			Method:      route.Method,
+			Path:        route.Path,
+			Description: ri.descriptions[routeKey(route.Method, route.Path)],
+			Handler:     route.Handler,
+		})
+	}
+	groups := make([]RouteGroup, 0, len(byPrefix))
+	for prefix, routes := range byPrefix {
+		sort.Slice(routes, func(i, j int) bool {
+			if routes[i].Path != routes[j].Path {
+				return routes[i].Path < routes[j].Path
+			}
+			return methodOrder(routes[i].Method) < methodOrder(routes[j].Method)
+		})
+		groups = append(groups, RouteGroup{Prefix: prefix, Routes: routes})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Prefix < groups[j].Prefix })
+	return groups
+}
+
+// Handler serves the route index as HTML or JSON.
+func (ri *RouteIndex) Handler(c *gin.Context) {
+	groups := ri.Groups()
+	if c.Query("format") == "json" || negotiate(c.GetHeader(hdrAccept), []string{mediaHTML, mediaJSON}) == mediaJSON {
+		JSONResult(c.Writer, groups)
+		return
+	}
+	ri.lock.RLock()
+	styleSheet := ri.styleSheet
+	ri.lock.RUnlock()
+	writePage(c.Writer, http.StatusOK, pageRoutes, &pageData{
+		Title:      "Routes",
+		StyleSheet: template.CSS(styleSheet),
+		Groups:     groups,
+	})
+}
+
+// routeKey returns the descriptions map key for a route.
+func routeKey(method, fullPath string) string {
+	return strings.ToUpper(method) + " " + fullPath
+}
+
+// routePrefix returns the first depth segments of the path, or "/" if there are none.
+func routePrefix(routePath string, depth int) string {
+	segments := strings.Split(strings.Trim(routePath, "/"), "/")
+	if depth >= len(segments) {
+		// Leaf routes are grouped with their parent.
+		depth = len(segments) - 1
+	}
+	if depth <= 0 {
+		return "/"
+	}
+	return "/" + strings.Join(segments[:depth], "/")
+}
+
+// joinRoutePaths joins a base path and a relative path as gin does,
+// keeping any trailing slash of the relative path.
+func joinRoutePaths(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	joined := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+// methodOrder returns the sort order for HTTP methods.
+func methodOrder(method string) int {
+	for i, m := range []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	} {
+		if method == m {
+			return i
+		}
+	}
+	return 99
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouteIndex() (*gin.Engine, *RouteIndex) {
+	router := gin.New()
+	index := NewRouteIndex(router)
+	index.GET(router, "/routes", "route index (this page)", index.Handler)
+	index.GET(router, "/ping", "server existence", Ping)
+	router.POST("/exit", Ping)
+	index.Describe(http.MethodPost, "/exit", "graceful <shut down>")
+	api := router.Group("/api/v1")
+	index.GET(api, "/things/:id", "get a thing", Ping)
+	index.Handle(api, http.MethodDelete, "/things/:id", "delete a thing", Ping)
+	api.GET("/things", Ping)
+	return router, index
+}
+
+func TestRouteIndex_Groups(t *testing.T) {
+	_, index := testRouteIndex()
+	groups := index.Groups()
+	require.Len(t, groups, 2)
+	assert.Equal(t, "/", groups[0].Prefix)
+	require.Len(t, groups[0].Routes, 3)
+	assert.Equal(t, RouteInfo{Method: http.MethodPost, Path: "/exit", Description: "graceful <shut down>",
+		Handler: groups[0].Routes[0].Handler}, groups[0].Routes[0])
+	assert.Contains(t, groups[0].Routes[0].Handler, "Ping")
+	assert.Equal(t, "/ping", groups[0].Routes[1].Path)
+	assert.Equal(t, "/routes", groups[0].Routes[2].Path)
+	assert.Equal(t, "/api", groups[1].Prefix)
+	require.Len(t, groups[1].Routes, 3)
+	assert.Equal(t, "/api/v1/things", groups[1].Routes[0].Path)
+	assert.Empty(t, groups[1].Routes[0].Description)
+	assert.Equal(t, http.MethodGet, groups[1].Routes[1].Method)
+	assert.Equal(t, "get a thing", groups[1].Routes[1].Description)
+	assert.Equal(t, http.MethodDelete, groups[1].Routes[2].Method)
+	assert.Equal(t, "delete a thing", groups[1].Routes[2].Description)
+
+	groups = index.GroupDepth(2).Groups()
+	require.Len(t, groups, 2)
+	assert.Equal(t, "/api/v1", groups[1].Prefix)
+	groups = index.GroupDepth(0).Groups()
+	require.Len(t, groups, 1)
+	assert.Equal(t, "/", groups[0].Prefix)
+}
+
+func TestRouteIndex_Handler(t *testing.T) {
+	router, _ := testRouteIndex()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/routes", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeHTMLValue, rec.Header().Get(hdrContentType))
+	body := rec.Body.String()
+	assert.Contains(t, body, `<span class="method post">POST</span>`)
+	assert.Contains(t, body, `<a href="/ping">/ping</a>`)
+	assert.Contains(t, body, "graceful &lt;shut down&gt;")
+	assert.Contains(t, body, `<td class="path">/api/v1/things/:id</td>`)
+	assert.NotContains(t, body, `<a href="/exit">`)
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/routes?format=json", nil),
+		httptest.NewRequest(http.MethodGet, "/routes", nil),
+	} {
+		if request.URL.RawQuery == "" {
+			request.Header.Set(hdrAccept, "application/json")
+		}
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, request)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, hdrContentTypeJSONValue, rec.Header().Get(hdrContentType))
+		var groups []RouteGroup
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &groups))
+		assert.Len(t, groups, 2)
+	}
+}
+
+func TestRoutePrefix(t *testing.T) {
+	assert.Equal(t, "/", routePrefix("/", 1))
+	assert.Equal(t, "/", routePrefix("/ping", 1))
+	assert.Equal(t, "/api", routePrefix("/api/things", 1))
+	assert.Equal(t, "/api", routePrefix("/api/things", 2))
+	assert.Equal(t, "/api/v1", routePrefix("/api/v1/things", 2))
+	assert.Equal(t, "/a/b/", joinRoutePaths("/a", "b/"))
+	assert.Equal(t, "/a", joinRoutePaths("/a", ""))
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Streaming constants.
+const (
+	hdrContentTypeNDJSONValue = "application/x-ndjson"
+	hdrContentTypeSSEValue    = "text/event-stream"
+	hdrAccelBuffering         = "X-Accel-Buffering"
+	streamFlushInterval       = 100 * time.Millisecond
+)
+
+// NDJSONResult writes the items produced by the iterator as newline-delimited JSON
+// (one JSON value per line, application/x-ndjson).
+// The iterator has the same signature as iter.Seq and stops when yield returns false.
+//
+// Like the other stream results, the response is written incrementally
+// instead of being marshaled into memory first, and data is flushed to the client periodically.
+// Streaming stops when the request context is done (the client disconnected or a deadline expired)
+// or a write fails and the error is returned.
+// Since the status and headers have already been sent by then, the response is truncated
+// and the error should just be logged.
+// Stream results must not be used in wrapped handlers with Options.Timeout or Options.Cache,
+// which buffer the whole response.
+func NDJSONResult[T any](writer http.ResponseWriter, request *http.Request, seq func(yield func(T) bool)) error {
+	stream := newStreamWriter(writer, request, hdrContentTypeNDJSONValue)
+	seq(func(item T) bool {
+		return stream.writeJSON(item, "", "\n")
+	})
+	return stream.finish()
+}
+
+// NDJSONChannelResult writes the items received from the channel as newline-delimited JSON
+// until the channel is closed (see NDJSONResult).
+// Data is flushed whenever no item is immediately available.
+func NDJSONChannelResult[T any](writer http.ResponseWriter, request *http.Request, items <-chan T) error {
+	stream := newStreamWriter(writer, request, hdrContentTypeNDJSONValue)
+	for {
+		var item T
+		var ok bool
+		select {
+		case item, ok = <-items:
+		default:
+			// Nothing waiting, send what has been written so far.
+			stream.flush()
+			select {
+			case item, ok = <-items:
+			case <-request.Context().Done():
+				return stream.finish()
+			}
+		}
+		if !ok || !stream.writeJSON(item, "", "\n") {
+			return stream.finish()
+		}
+	}
+}
+
+// JSONArrayResult writes the items produced by the iterator as a single JSON array,
+// encoding one item at a time so that large arrays are never held in memory (see NDJSONResult).
+// The iterator has the same signature as iter.Seq and stops when yield returns false.
+// If streaming stops early the closing bracket is not written
+// so that clients see invalid JSON instead of a silently truncated array.
+func JSONArrayResult[T any](writer http.ResponseWriter, request *http.Request, seq func(yield func(T) bool)) error {
+	stream := newStreamWriter(writer, request, hdrContentTypeJSONValue)
+	if !stream.write([]byte("[")) {
+		return stream.finish()
+	}
+	prefix := ""
+	seq(func(item T) bool {
+		if !stream.writeJSON(item, prefix, "") {
+			return false
+		}
+		prefix = ","
+		return true
+	})
+	if stream.err == nil {
+		stream.write([]byte("]"))
+	}
+	return stream.finish()
+}
+
+// SSEEvent is a single Server-Sent Event.
+type SSEEvent struct {
+	// Optional event ID, which the client sends back in the Last-Event-ID header when reconnecting.
+	ID string
+	// Optional event type, defaults to "message" on the client.
+	Event string
+	// Event data: strings (and byte slices) are sent as is and other values as JSON.
+	Data any
+	// Optional client reconnection time.
+	Retry time.Duration
+}
+
+// SSEResult sends the events received from the channel as Server-Sent Events (text/event-stream)
+// until the channel is closed (see NDJSONResult).
+// If heartbeat is greater than zero a comment line is sent whenever no event has been sent for that long,
+// which keeps proxies from closing idle connections and detects disconnected clients.
+func SSEResult(writer http.ResponseWriter, request *http.Request, events <-chan SSEEvent, heartbeat time.Duration) error {
+	writer.Header().Set(hdrCacheControl, "no-cache")
+	// Disable response buffering in nginx.
+	writer.Header().Set(hdrAccelBuffering, "no")
+	stream := newStreamWriter(writer, request, hdrContentTypeSSEValue)
+	stream.flush()
+	var ticker *time.Ticker
+	var beats <-chan time.Time
+	if heartbeat > 0 {
+		ticker = time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		beats = ticker.C
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return stream.finish()
+			}
+			data, err := event.format()
+			if err != nil {
+				stream.err = err
+				return stream.finish()
+			}
+			if !stream.write(data) {
+				return stream.finish()
+			}
+			stream.flush()
+			if ticker != nil {
+				ticker.Reset(heartbeat)
+			}
+		case <-beats:
+			if !stream.write([]byte(": heartbeat\n\n")) {
+				return stream.finish()
+			}
+			stream.flush()
+		case <-request.Context().Done():
+			return stream.finish()
+		}
+	}
+}
+
+// format returns the event in the text/event-stream format.
+func (se *SSEEvent) format() ([]byte, error) {
+	var data string
+	switch value := se.Data.(type) {
+	case string:
+		data = value
+	case []byte:
+		data = string(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal event data: %w", err)
+		}
+		data = string(encoded)
+	}
+	var builder strings.Builder
+	if se.ID != "" {
+		builder.WriteString("id: " + sseField(se.ID) + "\n")
+	}
+	if se.Event != "" {
+		builder.WriteString("event: " + sseField(se.Event) + "\n")
+	}
+	if se.Retry > 0 {
+		builder.WriteString("retry: " + strconv.FormatInt(se.Retry.Milliseconds(), 10) + "\n")
+	}
+	// Multi-line data is sent as multiple data lines, which the client joins with newlines.
+	// Clients treat CRLF, LF, and a lone CR as line terminators.
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		builder.WriteString("data: " + line + "\n")
+	}
+	builder.WriteString("\n")
+	return []byte(builder.String()), nil
+}
+
+// sseField removes line breaks, which would end a single line field early.
+func sseField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// streamWriter writes a streamed response, flushing periodically
+// and recording the first error (including request context cancellation).
+type streamWriter struct {
+	writer     http.ResponseWriter
+	request    *http.Request
+	controller *http.ResponseController
+	flushed    time.Time
+	err        error
+}
+
+// newStreamWriter sets the content type and sends the response header.
+func newStreamWriter(writer http.ResponseWriter, request *http.Request, contentType string) *streamWriter {
+	writer.Header().Set(hdrContentType, contentType)
+	writer.Header().Set(hdrContentTypeOptions, hdrContentTypeOptionsValue)
+	writer.WriteHeader(http.StatusOK)
+	return &streamWriter{
+		writer:     writer,
+		request:    request,
+		controller: http.NewResponseController(writer),
+		flushed:    time.Now(),
+	}
+}
+
+// write writes the data unless the request is done or a previous write failed,
+// flushing if it has been a while since the last flush.
+// Returns false if streaming should stop.
+func (sw *streamWriter) write(data []byte) bool {
+	if sw.err == nil {
+		sw.err = sw.request.Context().Err()
+	}
+	if sw.err != nil {
+		return false
+	}
+	if _, err := sw.writer.Write(data); err != nil {
+		sw.err = err
+		return false
+	}
+	if time.Since(sw.flushed) >= streamFlushInterval {
+		sw.flush()
+	}
+	return true
+}
+
+// writeJSON writes the item as JSON between the prefix and suffix.
+// Returns false if streaming should stop.
+func (sw *streamWriter) writeJSON(item any, prefix, suffix string) bool {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		if sw.err == nil {
+			sw.err = fmt.Errorf("marshal stream item: %w", err)
+		}
+		return false
+	}
+	return sw.write([]byte(prefix + string(encoded) + suffix))
+}
+
+// flush sends any buffered data to the client.
+func (sw *streamWriter) flush() {
+	if sw.err != nil {
+		return
+	}
+	if err := sw.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		sw.err = err
+	}
+	sw.flushed = time.Now()
+}
+
+// finish flushes any remaining data and returns the first error.
+func (sw *streamWriter) finish() error {
+	if sw.err == nil {
+		sw.err = sw.request.Context().Err()
+	}
+	sw.flush()
+	return sw.err
+}
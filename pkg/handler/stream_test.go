@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// streamItems returns an iterator over the items.
+func streamItems(items ...streamItem) func(yield func(streamItem) bool) {
+	return func(yield func(streamItem) bool) {
+		for _, item := range items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+func TestNDJSONResult(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, NDJSONResult(rec, req, streamItems(streamItem{1, "one"}, streamItem{2, "two"})))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, hdrContentTypeNDJSONValue, rec.Header().Get(hdrContentType))
+	assert.Equal(t, "{\"id\":1,\"name\":\"one\"}\n{\"id\":2,\"name\":\"two\"}\n", rec.Body.String())
+	assert.True(t, rec.Flushed)
+}
+
+func TestNDJSONResult_Canceled(t *testing.T) {
+	ctxt, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctxt)
+	count := 0
+	err := NDJSONResult(rec, req, func(yield func(int) bool) {
+		for {
+			count++
+			if count == 3 {
+				cancel()
+			}
+			if !yield(count) {
+				return
+			}
+		}
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "1\n2\n", rec.Body.String())
+}
+
+func TestNDJSONChannelResult(t *testing.T) {
+	items := make(chan streamItem)
+	go func() {
+		defer close(items)
+		items <- streamItem{1, "one"}
+		time.Sleep(10 * time.Millisecond)
+		items <- streamItem{2, "two"}
+	}()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, NDJSONChannelResult(rec, req, items))
+	assert.Equal(t, hdrContentTypeNDJSONValue, rec.Header().Get(hdrContentType))
+	assert.Equal(t, "{\"id\":1,\"name\":\"one\"}\n{\"id\":2,\"name\":\"two\"}\n", rec.Body.String())
+	assert.True(t, rec.Flushed)
+}
+
+func TestNDJSONChannelResult_Canceled(t *testing.T) {
+	ctxt, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctxt)
+	items := make(chan int, 1)
+	items <- 1
+	assert.ErrorIs(t, NDJSONChannelResult(rec, req, items), context.DeadlineExceeded)
+	assert.Equal(t, "1\n", rec.Body.String())
+}
+
+func TestJSONArrayResult(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, JSONArrayResult(rec, req, streamItems()))
+	assert.Equal(t, hdrContentTypeJSONValue, rec.Header().Get(hdrContentType))
+	assert.Equal(t, "[]", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, JSONArrayResult(rec, req, streamItems(streamItem{1, "one"}, streamItem{2, "two"})))
+	var items []streamItem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &items))
+	assert.Equal(t, []streamItem{{1, "one"}, {2, "two"}}, items)
+
+	// Marshal failure truncates the array.
+	rec = httptest.NewRecorder()
+	err := JSONArrayResult(rec, req, func(yield func(any) bool) {
+		_ = yield(1) && yield(func() {}) && yield(3)
+	})
+	assert.ErrorContains(t, err, "marshal stream item")
+	assert.Equal(t, "[1", rec.Body.String())
+}
+
+func TestSSEEvent_format(t *testing.T) {
+	event := SSEEvent{ID: "7\n", Event: "update", Data: "line one\r\nline two", Retry: 3 * time.Second}
+	data, err := event.format()
+	require.NoError(t, err)
+	assert.Equal(t, "id: 7\nevent: update\nretry: 3000\ndata: line one\ndata: line two\n\n", string(data))
+
+	event = SSEEvent{Data: []byte("one\rtwo\r\nthree\n\rfour")}
+	data, err = event.format()
+	require.NoError(t, err)
+	assert.Equal(t, "data: one\ndata: two\ndata: three\ndata: \ndata: four\n\n", string(data))
+
+	event = SSEEvent{Data: streamItem{1, "one"}}
+	data, err = event.format()
+	require.NoError(t, err)
+	assert.Equal(t, "data: {\"id\":1,\"name\":\"one\"}\n\n", string(data))
+
+	event = SSEEvent{Data: make(chan int)}
+	_, err = event.format()
+	assert.Error(t, err)
+}
+
+func TestSSEResult(t *testing.T) {
+	events := make(chan SSEEvent)
+	go func() {
+		defer close(events)
+		events <- SSEEvent{Event: "first", Data: "hello"}
+		time.Sleep(60 * time.Millisecond)
+		events <- SSEEvent{Data: 2}
+	}()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, SSEResult(rec, req, events, 20*time.Millisecond))
+	assert.Equal(t, hdrContentTypeSSEValue, rec.Header().Get(hdrContentType))
+	assert.Equal(t, "no-cache", rec.Header().Get(hdrCacheControl))
+	body := rec.Body.String()
+	assert.True(t, strings.HasPrefix(body, "event: first\ndata: hello\n\n"))
+	assert.Contains(t, body, ": heartbeat\n\n")
+	assert.True(t, strings.HasSuffix(body, "data: 2\n\n"))
+}
+
+func TestSSEResult_Disconnect(t *testing.T) {
+	done := make(chan error, 1)
+	router := gin.New()
+	router.GET("/events", func(c *gin.Context) {
+		events := make(chan SSEEvent) // never closed
+		go func() { events <- SSEEvent{Data: "ready"} }()
+		done <- SSEResult(c.Writer, c.Request, events, 10*time.Millisecond)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/events")
+	require.NoError(t, err)
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "data: ready\n", line)
+	require.NoError(t, response.Body.Close())
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "timeout waiting for disconnect")
+	}
+}
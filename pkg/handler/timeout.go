@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrHandlerTimeout is added to the gin.Context errors when a wrapped handler times out.
+var ErrHandlerTimeout = errors.New("handler timeout")
+
+// serveTimeout runs the middleware chain and wrapped handler with a deadline.
+//
+// The handler runs in a separate goroutine using a copy of the gin.Context
+// with a request context that is canceled at the deadline.
+// The response is buffered and only written if the handler finishes before the deadline.
+// Otherwise a timeout error response is written and any later writes by the handler are discarded.
+// A panic in the handler after the deadline can't be passed on to recovery middleware and is logged.
+//
+// If the request context is canceled first (usually because the client disconnected)
+// the handler is abandoned in the same way but no response is written.
+func (h *Wrapper) serveTimeout(ctx *gin.Context) {
+	parent := ctx.Request.Context()
+	// The gin.Context may be reused after an abandoned handler panics, so log copies of request fields.
+	route, method, path := ctx.FullPath(), ctx.Request.Method, ctx.Request.URL.Path
+	ctxt, cancel := context.WithTimeout(parent, h.options.Timeout)
+	defer cancel()
+
+	buffer := newBufferedWriter()
+	cp := ctx.Copy()
+	cp.Request = ctx.Request.WithContext(ctxt)
+	cp.Writer = buffer
+
+	done := make(chan struct{})
+	var (
+		panicked  any
+		abandoned bool
+		lock      sync.Mutex
+	)
+	go func() {
+		defer close(done)
+		defer func() {
+			recovered := recover()
+			lock.Lock()
+			defer lock.Unlock()
+			if abandoned {
+				if recovered != nil {
+					logLatePanic(route, method, path, recovered, debug.Stack())
+				}
+			} else {
+				// Pass panics to the request goroutine so that recovery middleware works.
+				panicked = recovered
+			}
+		}()
+		h.serve(cp, 0)
+	}()
+
+	select {
+	case <-done:
+		if panicked != nil {
+			panic(panicked)
+		}
+		ctx.Errors = append(ctx.Errors, cp.Errors...)
+		for key, value := range cp.Keys {
+			ctx.Set(key, value)
+		}
+		buffer.flushTo(ctx.Writer)
+		return
+	case <-ctxt.Done():
+	}
+
+	buffer.discard()
+	lock.Lock()
+	abandoned = true
+	// The handler may have panicked after the deadline but before it was abandoned.
+	if panicked != nil {
+		logLatePanic(route, method, path, panicked, nil)
+	}
+	lock.Unlock()
+
+	if err := parent.Err(); err != nil {
+		log.Debug().Str("route", route).Str("meth", method).Str("path", path).Err(err).
+			Msg("Request canceled before handler finished")
+		_ = ctx.Error(context.Cause(parent))
+		ctx.Abort()
+		return
+	}
+
+	code := h.options.TimeoutStatus
+	if code == 0 {
+		code = http.StatusServiceUnavailable
+	}
+	log.Warn().Str("route", route).Str("meth", method).Str("path", path).Dur("timeout", h.options.Timeout).
+		Msg("Handler timed out")
+	_ = ctx.Error(fmt.Errorf("%w after %s", ErrHandlerTimeout, h.options.Timeout))
+	NegotiatedErrorResult(ctx.Writer, ctx.Request, code, "Request timed out")
+}
+
+// logLatePanic logs a panic from a handler that has been abandoned after a timeout.
+func logLatePanic(route, method, path string, panicked any, stack []byte) {
+	event := log.Error().Str("route", route).Str("meth", method).Str("path", path).
+		Str("panic", fmt.Sprint(panicked))
+	if stack != nil {
+		event = event.Bytes("stack", stack)
+	}
+	event.Msg("Handler panicked after timeout")
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sleeper waits for the delay or the request context, then writes a response.
+// If the request context is canceled and release is not nil
+// the response is only written (or the panic raised) after release is closed.
+type sleeper struct {
+	delay    time.Duration
+	release  chan struct{}
+	panic    bool
+	canceled chan bool
+	writeErr chan error
+}
+
+func (s *sleeper) ServeHTTP(ctx *gin.Context) {
+	select {
+	case <-time.After(s.delay):
+		s.canceled <- false
+	case <-ctx.Request.Context().Done():
+		s.canceled <- true
+		if s.release != nil {
+			<-s.release
+		}
+	}
+	if s.panic {
+		panic("sleeper panic")
+	}
+	ctx.Header("X-Sleeper", "awake")
+	ctx.Status(http.StatusAccepted)
+	_, err := ctx.Writer.WriteString("finished")
+	s.writeErr <- err
+}
+
+// logRecords receives each log record written by zerolog.
+type logRecords chan string
+
+func (lr logRecords) Write(data []byte) (int, error) {
+	lr <- string(data)
+	return len(data), nil
+}
+
+func newSleeper(delay time.Duration) *sleeper {
+	return &sleeper{delay: delay, canceled: make(chan bool, 1), writeErr: make(chan error, 1)}
+}
+
+func TestWrapped_Timeout(t *testing.T) {
+	canServe := newSleeper(time.Millisecond)
+	hdlrFunc := NewWrapped(canServe, Options{Timeout: time.Second}).HandlerFunc()
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	hdlrFunc(ctx)
+	assert.False(t, <-canServe.canceled)
+	assert.NoError(t, <-canServe.writeErr)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "awake", rec.Header().Get("X-Sleeper"))
+	assert.Equal(t, "finished", rec.Body.String())
+	assert.Empty(t, ctx.Errors)
+}
+
+func TestWrapped_TimeoutExceeded(t *testing.T) {
+	canServe := newSleeper(time.Minute)
+	canServe.release = make(chan struct{})
+	hdlrFunc := NewWrapped(canServe, Options{Timeout: 10 * time.Millisecond}).HandlerFunc()
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	hdlrFunc(ctx)
+	close(canServe.release)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Request timed out")
+	require.Len(t, ctx.Errors, 1)
+	assert.True(t, errors.Is(ctx.Errors.Last().Err, ErrHandlerTimeout))
+
+	// Handler sees the canceled context and its late write is discarded.
+	assert.True(t, <-canServe.canceled)
+	assert.ErrorIs(t, <-canServe.writeErr, http.ErrHandlerTimeout)
+	assert.Empty(t, rec.Header().Get("X-Sleeper"))
+	assert.NotContains(t, rec.Body.String(), "finished")
+}
+
+func TestWrapped_TimeoutStatus(t *testing.T) {
+	canServe := newSleeper(time.Minute)
+	hdlrFunc := NewWrapped(canServe, Options{
+		Timeout:       10 * time.Millisecond,
+		TimeoutStatus: http.StatusGatewayTimeout,
+	}).HandlerFunc()
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set(hdrAccept, hdrContentTypeProblemJSONValue)
+	hdlrFunc(ctx)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Equal(t, hdrContentTypeProblemJSONValue, rec.Header().Get("Content-Type"))
+	assert.True(t, <-canServe.canceled)
+}
+
+func TestWrapped_TimeoutCanceled(t *testing.T) {
+	canServe := newSleeper(time.Minute)
+	hdlrFunc := NewWrapped(canServe, Options{Timeout: time.Minute}).HandlerFunc()
+	ctxt, cancel := context.WithCancel(context.Background())
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctxt)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	hdlrFunc(ctx)
+
+	// Client disconnect is not reported as a timeout.
+	assert.True(t, <-canServe.canceled)
+	assert.False(t, ctx.Writer.Written())
+	assert.Empty(t, rec.Body.String())
+	require.Len(t, ctx.Errors, 1)
+	assert.ErrorIs(t, ctx.Errors.Last().Err, context.Canceled)
+	assert.True(t, ctx.IsAborted())
+}
+
+func TestWrapped_TimeoutLatePanic(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	records := make(logRecords, 10)
+	log.Logger = zerolog.New(records)
+
+	canServe := newSleeper(time.Minute)
+	canServe.release = make(chan struct{})
+	canServe.panic = true
+	hdlrFunc := NewWrapped(canServe, Options{Timeout: 10 * time.Millisecond}).HandlerFunc()
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/late", nil)
+	hdlrFunc(ctx)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, <-records, "Handler timed out")
+	close(canServe.release)
+	select {
+	case record := <-records:
+		assert.Contains(t, record, "Handler panicked after timeout")
+		assert.Contains(t, record, `"panic":"sleeper panic"`)
+		assert.Contains(t, record, `"path":"/late"`)
+	case <-time.After(time.Second):
+		require.Fail(t, "late panic not logged")
+	}
+}
+
+func TestWrapped_TimeoutMiddlewareFromGin(t *testing.T) {
+	auth := MiddlewareFromGin(func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ErrorResult(ctx.Writer, http.StatusUnauthorized)
+			ctx.Abort()
+		}
+	})
+	noop := MiddlewareFromGin(func(ctx *gin.Context) {})
+	canServe := &wrapped{title: "Greetings", text: "Hello, world!"}
+	hdlrFunc := NewWrapped(canServe, Options{Middleware: []Middleware{noop, auth}, Timeout: time.Second}).HandlerFunc()
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set("Authorization", "yes")
+	hdlrFunc(ctx)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), canServe.text)
+
+	// Short-circuit in the auth middleware although the timeout copy of the context is always aborted.
+	rec = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	hdlrFunc(ctx)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotContains(t, rec.Body.String(), canServe.text)
+}
+
+func TestBufferedWriter_Discard(t *testing.T) {
+	buffer := newBufferedWriter()
+	_, err := buffer.WriteString("early")
+	require.NoError(t, err)
+	buffer.discard()
+	_, err = buffer.WriteString("late")
+	assert.ErrorIs(t, err, http.ErrHandlerTimeout)
+	assert.Equal(t, "early", string(buffer.contents()))
+}
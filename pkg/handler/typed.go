@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// TypedFunc is a handler function with typed request and response objects.
+// The request type must be a struct.
+type TypedFunc[Req, Resp any] func(ctxt context.Context, request Req) (Resp, error)
+
+// FieldError describes a single request validation failure.
+type FieldError struct {
+	// Name of the field as specified in the request (json, uri, form, or header tag).
+	Field string `json:"field"`
+	// Validation tag that failed (e.g. "required").
+	Tag string `json:"tag"`
+	// Validation tag parameter, if any (e.g. "3" for "min=3").
+	Param string `json:"param,omitempty"`
+	// Human-readable message.
+	Message string `json:"message"`
+}
+
+// Typed returns a gin.HandlerFunc for the specified TypedFunc.
+//
+// The request struct is bound from the JSON body (json tags), path parameters (uri tags),
+// query parameters (form tags), and headers (header tags) in that order,
+// so that body values never override path, query, or header values,
+// and then validated using gin's validator (binding tags).
+// Path, query, and header values are only bound to fields with the matching tag
+// and fields with only uri, form, or header tags are never bound from the body,
+// so that a client can't set a header field (which may be trusted) from the query or body.
+// Binding failures return 400 Bad Request and validation failures return
+// 400 Bad Request with an "errors" extension containing a FieldError for each failure.
+//
+// A successful response is rendered via JSONResult and errors are rendered as for NewWrappedResult.
+func Typed[Req, Resp any](fn TypedFunc[Req, Resp], options Options) gin.HandlerFunc {
+	return NewTyped(fn, options).HandlerFunc()
+}
+
+// NewTyped returns a new wrapped handler for the specified TypedFunc (see Typed).
+// If the Options include an Operation, its Request and Response are set
+// from the request and response types unless they are already specified,
+// so that the handler can be documented via OpenAPI.HandleWrapped.
+func NewTyped[Req, Resp any](fn TypedFunc[Req, Resp], options Options) *Wrapper {
+	if options.Operation != nil {
+		operation := *options.Operation
+		if operation.Request == nil {
+			operation.Request = new(Req)
+		}
+		if operation.Response == nil {
+			operation.Response = new(Resp)
+		}
+		options.Operation = &operation
+	}
+	return NewWrapped(&typedServer[Req, Resp]{fn: fn, mapper: options.ErrorMapper}, options)
+}
+
+// typedServer adapts a TypedFunc to CanServe.
+type typedServer[Req, Resp any] struct {
+	fn     TypedFunc[Req, Resp]
+	mapper *ErrorMapper
+}
+
+func (ts *typedServer[Req, Resp]) ServeHTTP(ctx *gin.Context) {
+	var request Req
+	if problem := bindRequest(ctx, &request); problem != nil {
+		_ = ctx.Error(errors.New(problem.Detail)).SetType(gin.ErrorTypeBind)
+		NegotiatedProblemResult(ctx.Writer, ctx.Request, problem)
+		return
+	}
+	if response, err := ts.fn(ctx.Request.Context(), request); err != nil {
+		renderError(ctx, ts.mapper, err)
+	} else {
+		JSONResult(ctx.Writer, response)
+	}
+}
+
+// bindRequest binds and validates the request object.
+// A Problem is returned if binding or validation fails.
+func bindRequest(ctx *gin.Context, request any) *Problem {
+	// The body is decoded first so that path, query, and header values can't be overridden by it
+	// (encoding/json also matches untagged fields by name, case-insensitively).
+	if ctx.Request.Body != nil && ctx.Request.ContentLength != 0 {
+		if err := json.NewDecoder(ctx.Request.Body).Decode(request); err != nil && !errors.Is(err, io.EOF) {
+			return NewProblem(http.StatusBadRequest, "Bad JSON body: "+err.Error())
+		}
+		clearSourceFields(reflect.ValueOf(request).Elem())
+	}
+	uri := make(map[string][]string, len(ctx.Params))
+	for _, param := range ctx.Params {
+		uri[param.Key] = []string{param.Value}
+	}
+	if err := mapTagged(request, uri, "uri"); err != nil {
+		return NewProblem(http.StatusBadRequest, "Bad path parameter: "+err.Error())
+	}
+	if err := mapTagged(request, ctx.Request.URL.Query(), "form"); err != nil {
+		return NewProblem(http.StatusBadRequest, "Bad query parameter: "+err.Error())
+	}
+	// Header tags may or may not be in canonical form.
+	header := make(map[string][]string)
+	for _, name := range tagNames(reflect.TypeOf(request).Elem(), "header") {
+		if values := ctx.Request.Header.Values(name); len(values) > 0 {
+			header[name] = values
+		}
+	}
+	if err := mapTagged(request, header, "header"); err != nil {
+		return NewProblem(http.StatusBadRequest, "Bad header: "+err.Error())
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	if err := binding.Validator.ValidateStruct(request); err != nil {
+		problem := NewProblem(http.StatusBadRequest, "Request validation failed")
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			fieldErrors := make([]FieldError, len(validationErrors))
+			for i, fe := range validationErrors {
+				fieldErrors[i] = FieldError{
+					Field:   fieldName(reflect.TypeOf(request), fe.StructNamespace()),
+					Tag:     fe.Tag(),
+					Param:   fe.Param(),
+					Message: fieldMessage(fe),
+				}
+			}
+			problem.Extensions = map[string]any{"errors": fieldErrors}
+		} else {
+			problem.Detail = err.Error()
+		}
+		return problem
+	}
+	return nil
+}
+
+// mapTagged sets the fields of the request that have the tag from the values.
+// binding.MapFormWithTag also matches fields without the tag by their Go name,
+// so the values are mapped into a new request object and only fields with the tag are copied.
+func mapTagged(request any, values map[string][]string, tag string) error {
+	target := reflect.ValueOf(request).Elem()
+	mapped := reflect.New(target.Type())
+	if err := binding.MapFormWithTag(mapped.Interface(), values, tag); err != nil {
+		return err
+	}
+	copyTagged(target, mapped.Elem(), values, tag)
+	return nil
+}
+
+// copyTagged copies the struct fields with the tag that have a value (or a default) from src to dst,
+// including fields of nested structs. Returns true if any field was copied.
+func copyTagged(dst, src reflect.Value, values map[string][]string, tag string) bool {
+	copied := false
+	for i := 0; i < dst.NumField(); i++ {
+		field := dst.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get(tag), ",")
+		switch {
+		case name == "-":
+		case name != "":
+			if _, found := values[name]; found || strings.Contains(options, "default=") {
+				dst.Field(i).Set(src.Field(i))
+				copied = true
+			}
+		case field.Type.Kind() == reflect.Struct:
+			copied = copyTagged(dst.Field(i), src.Field(i), values, tag) || copied
+		case field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct && !src.Field(i).IsNil():
+			if dst.Field(i).IsNil() {
+				fresh := reflect.New(field.Type.Elem())
+				if copyTagged(fresh.Elem(), src.Field(i).Elem(), values, tag) {
+					dst.Field(i).Set(fresh)
+					copied = true
+				}
+			} else {
+				copied = copyTagged(dst.Field(i).Elem(), src.Field(i).Elem(), values, tag) || copied
+			}
+		}
+	}
+	return copied
+}
+
+// tagNames returns the names in the tag for the fields of the struct type, including nested structs.
+func tagNames(typ reflect.Type, tag string) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" {
+			if name != "-" {
+				names = append(names, name)
+			}
+		} else if fieldType.Kind() == reflect.Struct {
+			names = append(names, tagNames(fieldType, tag)...)
+		}
+	}
+	return names
+}
+
+// clearSourceFields resets struct fields that have a uri, form, or header tag but no json tag,
+// which encoding/json would otherwise match by name in the body.
+func clearSourceFields(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("json") != "" {
+			continue
+		}
+		if field.Tag.Get("uri") != "" || field.Tag.Get("form") != "" || field.Tag.Get("header") != "" {
+			value.Field(i).SetZero()
+		} else if field.Type.Kind() == reflect.Struct {
+			clearSourceFields(value.Field(i))
+		} else if field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct && !value.Field(i).IsNil() {
+			clearSourceFields(value.Field(i).Elem())
+		}
+	}
+}
+
+// fieldTags lists the tags checked for the request name of a field, in order.
+var fieldTags = []string{"json", "uri", "form", "header"}
+
+// fieldName returns the request name of the field at the specified struct namespace
+// (e.g. "Request.Address.City") using the first available tag for each field.
+func fieldName(typ reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")
+	names := make([]string, 0, len(parts))
+	for _, part := range parts[1:] {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		// Strip any index suffix (e.g. "Items[0]").
+		fieldPart, index, _ := strings.Cut(part, "[")
+		if index != "" {
+			index = "[" + index
+		}
+		name := fieldPart
+		if typ.Kind() == reflect.Struct {
+			if field, found := typ.FieldByName(fieldPart); found {
+				for _, tag := range fieldTags {
+					if tagName, _, _ := strings.Cut(field.Tag.Get(tag), ","); tagName != "" && tagName != "-" {
+						name = tagName
+						break
+					}
+				}
+				typ = field.Type
+			}
+		}
+		names = append(names, name+index)
+	}
+	return strings.Join(names, ".")
+}
+
+// fieldMessage returns a human-readable message for a validation failure.
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fe.Param())
+	default:
+		if fe.Param() != "" {
+			return fmt.Sprintf("failed %s=%s validation", fe.Tag(), fe.Param())
+		}
+		return fmt.Sprintf("failed %s validation", fe.Tag())
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type typedRequest struct {
+	ID      int          `uri:"id" binding:"required,min=1"`
+	Verbose bool         `form:"verbose"`
+	Tenant  string       `header:"X-Tenant" binding:"required"`
+	Name    string       `json:"name" binding:"required,max=8"`
+	Address typedAddress `json:"address"`
+}
+
+type typedResponse struct {
+	ID      int    `json:"id"`
+	Verbose bool   `json:"verbose"`
+	Tenant  string `json:"tenant"`
+	Name    string `json:"name"`
+	City    string `json:"city"`
+}
+
+func typedRouter() *gin.Engine {
+	router := gin.New()
+	router.POST("/thing/:id", Typed(func(_ context.Context, request typedRequest) (typedResponse, error) {
+		if request.Name == "missing" {
+			return typedResponse{}, NewStatusError(http.StatusNotFound, "No such thing", nil)
+		}
+		return typedResponse{
+			ID:      request.ID,
+			Verbose: request.Verbose,
+			Tenant:  request.Tenant,
+			Name:    request.Name,
+			City:    request.Address.City,
+		}, nil
+	}, Options{}))
+	return router
+}
+
+func typedServe(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("X-Tenant", "acme")
+	request.Header.Set(hdrAccept, "application/json")
+	router.ServeHTTP(rec, request)
+	return rec
+}
+
+func TestTyped(t *testing.T) {
+	rec := typedServe(typedRouter(), "/thing/23?verbose=true", `{"name":"Fred","address":{"city":"Paris"}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response typedResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, typedResponse{ID: 23, Verbose: true, Tenant: "acme", Name: "Fred", City: "Paris"}, response)
+}
+
+func TestTyped_BodyCannotOverride(t *testing.T) {
+	// Untagged fields are matched by name in the JSON body.
+	rec := typedServe(typedRouter(), "/thing/23?verbose=true",
+		`{"id":999,"Tenant":"evil","verbose":false,"name":"Fred","address":{"city":"Paris"}}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var response typedResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, typedResponse{ID: 23, Verbose: true, Tenant: "acme", Name: "Fred", City: "Paris"}, response)
+}
+
+func TestTyped_TaggedFieldsOnly(t *testing.T) {
+	type userRequest struct {
+		UserID string `header:"X-User-ID"`
+		Name   string `json:"name"`
+		Page   int    `form:"page,default=1"`
+	}
+	router := gin.New()
+	router.POST("/user", Typed(func(_ context.Context, request userRequest) (userRequest, error) {
+		return request, nil
+	}, Options{}))
+	serve := func(path, body string, header map[string]string) userRequest {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response userRequest
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	// Query parameters and body fields matching Go field names are ignored.
+	assert.Equal(t, userRequest{Name: "fromBody", Page: 1},
+		serve("/user?UserID=spoofed&Name=fromQuery", `{"name":"fromBody","UserID":"spoofed"}`, nil))
+	assert.Equal(t, userRequest{UserID: "alice", Page: 2},
+		serve("/user?page=2", "", map[string]string{"X-User-ID": "alice", "Name": "fromHeader"}))
+
+	// Nested untagged fields are not set from the query.
+	rec := typedServe(typedRouter(), "/thing/23?City=London", `{"name":"Fred","address":{"city":"Paris"}}`)
+	var response typedResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "Paris", response.City)
+}
+
+func TestTyped_Errors(t *testing.T) {
+	router := typedRouter()
+	rec := typedServe(router, "/thing/23", `{"name":"missing","address":{"city":"Paris"}}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = typedServe(router, "/thing/abc", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Bad path parameter")
+
+	rec = typedServe(router, "/thing/23", `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Bad JSON body")
+}
+
+func TestTyped_Validation(t *testing.T) {
+	rec := typedServe(typedRouter(), "/thing/0", `{"name":"Frederick the Great"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var problem struct {
+		Detail string       `json:"detail"`
+		Errors []FieldError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "Request validation failed", problem.Detail)
+	assert.ElementsMatch(t, []FieldError{
+		{Field: "id", Tag: "required", Message: "is required"},
+		{Field: "name", Tag: "max", Param: "8", Message: "must be at most 8"},
+		{Field: "address.city", Tag: "required", Message: "is required"},
+	}, problem.Errors)
+}
@@ -1,9 +1,8 @@
 package handler
 
 import (
-	"fmt"
+	"html/template"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -17,6 +16,10 @@ import (
 // Since the signal goes to the current process there is no need to pass in an http.Server.
 //
 // Note that executing this function is likely suicide for the parent process.
+//
+// Deprecated: Exit runs on any request method without authorization,
+// so a crawler or browser prefetch can shut down the server.
+// Use ExitWithOptions instead.
 func Exit(c *gin.Context) {
 	if err := server.Interrupt(); err != nil {
 		log.Logger.Error().Err(err).Msg("Unable to interrupt server")
@@ -72,35 +75,25 @@ type LinkDef struct {
 
 // Links returns an HTML page with a short list of links to useful server URLs.
 // The links are provided by argument and are displayed in a simple table.
+// Link fields are escaped so they may contain arbitrary text.
 // A stylesheet may be provided (and may be "") or the default LinkTableStyleSheet is used.
+// The stylesheet is not escaped and must be trusted.
 //
 // This function can't be passed directly as a handler as it has too many arguments.
 // Surround it with another function that has a single *gin.Context argument
 // and no return values.
 //
 // The server must be configured with all defined links.
+// See RouteIndex for an index page built automatically from the configured routes.
 func Links(c *gin.Context, styleSheet string, links ...LinkDef) {
-	var page strings.Builder
-	page.WriteString("<head>\n  <title>Links</title>")
 	if styleSheet == "" {
 		styleSheet = LinkTableStyleSheet
 	}
-	page.WriteString("  <style>\n")
-	page.WriteString(styleSheet)
-	page.WriteString("  </style>\n")
-	page.WriteString("</head>\n<body>\n")
-	page.WriteString("  <table class=\"links\">\n")
-	for _, link := range links {
-		_, _ = fmt.Fprintf(&page,
-			"<tr>"+
-				"<td class=\"link\"><a href=\"%s\">%s</a></td>"+
-				"<td class=\"spacer\"></td>"+
-				"<td class=\"descr\">%s</td>"+
-				"</tr>\n",
-			link.Path, link.Name, link.Description)
-	}
-	page.WriteString("  </table>\n</body>\n")
-	writePage(c.Writer, "links", page.String())
+	writePage(c.Writer, http.StatusOK, pageLinks, &pageData{
+		Title:      "Links",
+		StyleSheet: template.CSS(styleSheet),
+		Links:      links,
+	})
 }
 
 //------------------------------------------------------------------------
@@ -111,32 +104,3 @@ func Ping(c *gin.Context) {
 		"message": "pong",
 	})
 }
-
-//////////////////////////////////////////////////////////////////////////
-
-const htmlCenteredText = `
-<head><title>[TITLE]</title></head>
-<body><center>[TEXT]</center></body>
-`
-
-// centeredText returns an HTML page with the specified title and
-// the specified text centered on the page.
-func centeredText(title string, text string) string {
-	return strings.Replace(strings.Replace(htmlCenteredText,
-		"[TITLE]", title, 1),
-		"[TEXT]", text, 1)
-}
-
-// writePage writes an HTML page.
-func writePage(writer http.ResponseWriter, pageName string, html string) {
-	if _, err := writer.Write([]byte(html)); err != nil {
-		writer.WriteHeader(http.StatusInternalServerError)
-		log.Logger.Error().Err(err).Str("page", pageName).Msg("Writing page")
-		_, _ = writer.Write([]byte("Error: " + err.Error()))
-	}
-}
-
-// writeCenteredText writes an HTML page with centered text.
-func writeCenteredText(writer http.ResponseWriter, title, text string) {
-	writePage(writer, title, centeredText(title, text))
-}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,11 +13,87 @@ type CanServe interface {
 	ServeHTTP(ctx *gin.Context)
 }
 
+// CanServeResult is a handler that returns a result object or an error
+// instead of writing the response itself.
+// A non-nil result is rendered via JSONResult,
+// a nil result with no error results in a 204 No Content response.
+type CanServeResult interface {
+	ServeResult(ctx *gin.Context) (any, error)
+}
+
+// CanServeError is a handler that writes its own successful response
+// but returns any error instead of writing an error response.
+type CanServeError interface {
+	ServeError(ctx *gin.Context) error
+}
+
 // Options that can be specified to the Wrapper.
 type Options struct {
-	// Allow Cross-Origin Resource Sharing (CORS).
+	// Allow Cross-Origin Resource Sharing (CORS) from any origin.
 	// This will probably only be true for testing.
+	//
+	// Deprecated: Use the CORS field which supports a full CORS policy.
 	Cors bool
+
+	// Optional Cross-Origin Resource Sharing (CORS) policy.
+	// Register the wrapped handler for OPTIONS as well so that
+	// preflight requests are answered, or use CORS.Middleware instead.
+	CORS *CORS
+
+	// Optional mapper for errors returned by CanServeResult or CanServeError handlers.
+	// If nil the status code is still extracted from errors that implement StatusCoder.
+	ErrorMapper *ErrorMapper
+
+	// Ordered list of middleware run around the wrapped handler.
+	// The first middleware in the list is the outermost.
+	// CORS handling (if any) is done before any middleware.
+	Middleware []Middleware
+
+	// Optional response caching for GET and HEAD requests (ETag, conditional requests, Cache-Control).
+	// Caching is done inside the middleware chain so that middleware
+	// (e.g. authentication) still runs for responses served from the cache Store.
+	// Add request headers that select per-user responses (e.g. Authorization) to the KeyHeaders.
+	Cache *Cache
+
+	// Optional time limit for the middleware chain and wrapped handler.
+	// The request context is canceled at the deadline and a timeout error response is returned.
+	// The response is buffered so that nothing written after the deadline reaches the client.
+	Timeout time.Duration
+
+	// Status code for timed out requests, defaults to 503 Service Unavailable.
+	// Use 504 Gateway Timeout for handlers that mostly wait on downstream services.
+	TimeoutStatus int
+
+	// Optional OpenAPI documentation for the handler, see OpenAPI.HandleWrapped.
+	Operation *Operation
+}
+
+// Middleware wraps the serving of a wrapped handler.
+// Call next to continue with the next middleware or the wrapped handler.
+// Return without calling next to short-circuit the chain,
+// usually after writing a response (e.g. an authorization failure).
+type Middleware func(ctx *gin.Context, next func())
+
+// MiddlewareFromGin converts a gin.HandlerFunc into a Middleware.
+// The chain continues unless the handler function calls gin.Context.Abort.
+// The handler function must not call gin.Context.Next,
+// which would run the rest of the gin handler chain instead of the Middleware chain.
+//
+// The gin.Context copy used with Options.Timeout is always aborted (see gin.Context.Copy),
+// so a call to Abort can't be detected and the chain stops if the handler function writes a response
+// (as Auth, RateLimiter, and LoopbackOnly do when they reject a request).
+func MiddlewareFromGin(handlerFunc gin.HandlerFunc) Middleware {
+	return func(ctx *gin.Context, next func()) {
+		aborted, written := ctx.IsAborted(), ctx.Writer.Written()
+		handlerFunc(ctx)
+		if aborted {
+			if written || !ctx.Writer.Written() {
+				next()
+			}
+		} else if !ctx.IsAborted() {
+			next()
+		}
+	}
 }
 
 // Wrapper composed of a CanServe instance and Options.
@@ -32,14 +111,94 @@ func NewWrapped(wrapped CanServe, options Options) *Wrapper {
 	}
 }
 
+// NewWrappedResult returns a new wrapped handler for a CanServeResult.
+// Errors are rendered via the ErrorMapper in the Options.
+func NewWrappedResult(wrapped CanServeResult, options Options) *Wrapper {
+	return NewWrapped(&resultServer{wrapped: wrapped, mapper: options.ErrorMapper}, options)
+}
+
+// NewWrappedError returns a new wrapped handler for a CanServeError.
+// Errors are rendered via the ErrorMapper in the Options.
+func NewWrappedError(wrapped CanServeError, options Options) *Wrapper {
+	return NewWrapped(&errorServer{wrapped: wrapped, mapper: options.ErrorMapper}, options)
+}
+
 // HandlerFunc returns a gin.HandlerFunc for use with one or more links.
 func (h *Wrapper) HandlerFunc() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if h.options.Cors {
+		if h.options.CORS != nil {
+			if h.options.CORS.Handle(ctx) {
+				// Preflight request has been answered.
+				return
+			}
+		} else if h.options.Cors {
 			// CORS is necessary for testing locally but should not be there in production.
-			ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			ctx.Writer.Header().Set(hdrAllowOrigin, corsWildcard)
+		}
+
+		if h.options.Timeout > 0 {
+			h.serveTimeout(ctx)
+		} else {
+			h.serve(ctx, 0)
 		}
+	}
+}
 
+// serve runs the middleware at the specified index or,
+// after the last middleware, the wrapped handler.
+func (h *Wrapper) serve(ctx *gin.Context, index int) {
+	if index < len(h.options.Middleware) {
+		h.options.Middleware[index](ctx, func() { h.serve(ctx, index+1) })
+	} else if h.options.Cache != nil {
+		h.options.Cache.serve(ctx, func() { h.wrapped.ServeHTTP(ctx) })
+	} else {
 		h.wrapped.ServeHTTP(ctx)
 	}
 }
+
+//////////////////////////////////////////////////////////////////////////
+
+// resultServer adapts a CanServeResult to CanServe.
+type resultServer struct {
+	wrapped CanServeResult
+	mapper  *ErrorMapper
+}
+
+func (rs *resultServer) ServeHTTP(ctx *gin.Context) {
+	if result, err := rs.wrapped.ServeResult(ctx); err != nil {
+		renderError(ctx, rs.mapper, err)
+	} else if result == nil {
+		ctx.Status(http.StatusNoContent)
+		ctx.Writer.WriteHeaderNow()
+	} else {
+		JSONResult(ctx.Writer, result)
+	}
+}
+
+// errorServer adapts a CanServeError to CanServe.
+type errorServer struct {
+	wrapped CanServeError
+	mapper  *ErrorMapper
+}
+
+func (es *errorServer) ServeHTTP(ctx *gin.Context) {
+	if err := es.wrapped.ServeError(ctx); err != nil {
+		renderError(ctx, es.mapper, err)
+	}
+}
+
+// renderError adds the error to the gin.Context and renders the mapped response
+// unless a response has already been written.
+// Errors mapped to client error status codes are marked public
+// so that they are not logged as server errors.
+// The error is logged from the gin.Context errors by the ginzero logger, not here.
+func renderError(ctx *gin.Context, mapper *ErrorMapper, err error) {
+	ginErr := ctx.Error(err)
+	mapping, _ := mapper.Map(err)
+	if mapping.Status < http.StatusInternalServerError {
+		ginErr.SetType(gin.ErrorTypePublic)
+	}
+	if !ctx.Writer.Written() {
+		renderMapping(ctx, mapping)
+	}
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -71,3 +75,114 @@ func TestWrapped_Cors(t *testing.T) {
 	assert.Len(t, corsHdr, 1)
 	assert.Equal(t, valCORS, corsHdr[0])
 }
+
+type resulted struct {
+	result any
+	err    error
+}
+
+func (r *resulted) ServeResult(_ *gin.Context) (any, error) {
+	return r.result, r.err
+}
+
+func (r *resulted) ServeError(ctx *gin.Context) error {
+	if r.err == nil {
+		ctx.String(http.StatusOK, "%v", r.result)
+	}
+	return r.err
+}
+
+func TestWrappedResult(t *testing.T) {
+	mapper := NewErrorMapper().Register(errNotFound, http.StatusNotFound, "No such record")
+	for _, test := range []struct {
+		served *resulted
+		code   int
+		body   string
+	}{
+		{&resulted{result: map[string]string{"name": "Fred"}}, http.StatusOK, `{"name":"Fred"}`},
+		{&resulted{}, http.StatusNoContent, ""},
+		{&resulted{err: errNotFound}, http.StatusNotFound, "No such record"},
+		{&resulted{err: NewStatusError(http.StatusConflict, "Already exists", errors.New("dup key"))},
+			http.StatusConflict, "Already exists"},
+		{&resulted{err: errors.New("disk full")}, http.StatusInternalServerError, "Internal Server Error"},
+	} {
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		NewWrappedResult(test.served, Options{ErrorMapper: mapper}).HandlerFunc()(ctx)
+		assert.Equal(t, test.code, rec.Code)
+		assert.Contains(t, rec.Body.String(), test.body)
+		assert.NotContains(t, rec.Body.String(), "dup key")
+		assert.NotContains(t, rec.Body.String(), "disk full")
+		if test.served.err != nil {
+			require.Len(t, ctx.Errors, 1)
+			assert.Equal(t, test.code < 500, ctx.Errors[0].IsType(gin.ErrorTypePublic))
+		}
+	}
+}
+
+func TestWrappedError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	NewWrappedError(&resulted{result: "fine"}, Options{}).HandlerFunc()(ctx)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "fine", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	NewWrappedError(&resulted{err: NewStatusError(http.StatusBadRequest, "", nil)}, Options{}).HandlerFunc()(ctx)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// The error is left in the gin.Context for the ginzero logger instead of being logged here.
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(buffer)
+	rec = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	NewWrappedError(&resulted{err: errors.New("broken")}, Options{}).HandlerFunc()(ctx)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	require.Len(t, ctx.Errors, 1)
+	assert.Empty(t, buffer.String())
+}
+
+func TestWrapped_Middleware(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(ctx *gin.Context, next func()) {
+			order = append(order, name+" before")
+			next()
+			order = append(order, name+" after")
+		}
+	}
+	auth := MiddlewareFromGin(func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			ErrorResult(ctx.Writer, http.StatusUnauthorized)
+			ctx.Abort()
+		}
+	})
+	canServe := &wrapped{title: "Greetings", text: "Hello, world!"}
+	hdlrFunc := NewWrapped(canServe, Options{Middleware: []Middleware{trace("outer"), auth, trace("inner")}}).HandlerFunc()
+
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set("Authorization", "yes")
+	hdlrFunc(ctx)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), canServe.text)
+	assert.Equal(t, []string{"outer before", "inner before", "inner after", "outer after"}, order)
+
+	// Short-circuit in the auth middleware.
+	order = nil
+	rec = httptest.NewRecorder()
+	ctx, _ = gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	hdlrFunc(ctx)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotContains(t, rec.Body.String(), canServe.text)
+	assert.Equal(t, []string{"outer before", "outer after"}, order)
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Default histogram bucket upper bounds.
+var (
+	// DefaultDurationBuckets are in seconds.
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// DefaultSizeBuckets are in bytes.
+	DefaultSizeBuckets = []float64{100, 1000, 10_000, 100_000, 1_000_000, 10_000_000}
+)
+
+// Options that can be specified to NewCollector.
+type Options struct {
+	// Optional prefix for all metric names, separated from the name by an underscore.
+	Namespace string
+
+	// Histogram bucket upper bounds for request duration in seconds.
+	// Defaults to DefaultDurationBuckets.
+	DurationBuckets []float64
+
+	// Histogram bucket upper bounds for response size in bytes.
+	// Defaults to DefaultSizeBuckets.
+	SizeBuckets []float64
+}
+
+// Collector collects request metrics via Middleware and serves them via Handler.
+type Collector struct {
+	requests  *counterVec
+	duration  *histogramVec
+	size      *histogramVec
+	inFlight  *gaugeVec
+	collected []family
+}
+
+// NewCollector returns a new Collector configured by the specified options.
+func NewCollector(options Options) *Collector {
+	prefix := ""
+	if options.Namespace != "" {
+		prefix = options.Namespace + "_"
+	}
+	if options.DurationBuckets == nil {
+		options.DurationBuckets = DefaultDurationBuckets
+	}
+	if options.SizeBuckets == nil {
+		options.SizeBuckets = DefaultSizeBuckets
+	}
+	labels := []string{"method", "route", "status"}
+	c := &Collector{
+		requests: newCounterVec(prefix+"http_requests_total",
+			"Total number of HTTP requests.", labels),
+		duration: newHistogramVec(prefix+"http_request_duration_seconds",
+			"HTTP request duration in seconds.", labels, options.DurationBuckets),
+		size: newHistogramVec(prefix+"http_response_size_bytes",
+			"HTTP response size in bytes.", labels, options.SizeBuckets),
+		inFlight: newGaugeVec(prefix+"http_requests_in_flight",
+			"Number of HTTP requests currently being served.", []string{"method", "route"}),
+	}
+	c.collected = []family{c.requests, c.duration, c.size, c.inFlight}
+	return c
+}
+
+// Middleware returns a gin middleware function that records metrics for each request.
+func (c *Collector) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		method := methodLabel(ctx.Request.Method)
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		c.inFlight.add(1, method, route)
+		defer c.inFlight.add(-1, method, route)
+
+		ctx.Next()
+
+		status := statusClass(ctx.Writer.Status())
+		c.requests.inc(method, route, status)
+		c.duration.observe(time.Since(start).Seconds(), method, route, status)
+		c.size.observe(float64(max(ctx.Writer.Size(), 0)), method, route, status)
+	}
+}
+
+// Handler serves the collected metrics in Prometheus text exposition format.
+func (c *Collector) Handler(ctx *gin.Context) {
+	ctx.Writer.Header().Set("Content-Type", contentType)
+	ctx.Writer.WriteHeader(http.StatusOK)
+	if err := writeFamilies(ctx.Writer, c.collected); err != nil {
+		log.Logger.Error().Err(err).Msg("Writing metrics")
+	}
+}
+
+// methodLabel returns the standard HTTP method or "OTHER" for any other method,
+// since the request method is chosen by the client and each value would add new series.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+// statusClass returns the class of the specified status code (e.g. "2xx").
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return strconv.Itoa(code)
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector(Options{
+		Namespace:       "test",
+		DurationBuckets: []float64{1, 10},
+		SizeBuckets:     []float64{1, 10},
+	})
+	require.NotNil(t, collector)
+	router := gin.New()
+	router.Use(collector.Middleware())
+	router.GET("/metrics", collector.Handler)
+	router.GET("/item/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "item")
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"/item/1", "/item/2", "/fail", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("FOOBAR", "/item/3", nil))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "# HELP test_http_requests_total Total number of HTTP requests.\n")
+	assert.Contains(t, body, "# TYPE test_http_requests_total counter\n")
+	assert.Contains(t, body, `test_http_requests_total{method="GET",route="/item/:id",status="2xx"} 2`+"\n")
+	assert.Contains(t, body, `test_http_requests_total{method="GET",route="/fail",status="5xx"} 1`+"\n")
+	assert.Contains(t, body, `test_http_requests_total{method="GET",route="unmatched",status="4xx"} 1`+"\n")
+	assert.Contains(t, body, `test_http_requests_total{method="OTHER",route="unmatched",status="4xx"} 1`+"\n")
+	assert.NotContains(t, body, "FOOBAR")
+	assert.Contains(t, body, "# TYPE test_http_request_duration_seconds histogram\n")
+	assert.Contains(t, body, `test_http_request_duration_seconds_bucket{method="GET",route="/item/:id",status="2xx",le="+Inf"} 2`+"\n")
+	assert.Contains(t, body, `test_http_request_duration_seconds_count{method="GET",route="/item/:id",status="2xx"} 2`+"\n")
+	assert.Contains(t, body, `test_http_response_size_bytes_bucket{method="GET",route="/item/:id",status="2xx",le="1"} 0`+"\n")
+	assert.Contains(t, body, `test_http_response_size_bytes_bucket{method="GET",route="/item/:id",status="2xx",le="10"} 2`+"\n")
+	assert.Contains(t, body, `test_http_response_size_bytes_sum{method="GET",route="/item/:id",status="2xx"} 8`+"\n")
+	assert.Contains(t, body, "# TYPE test_http_requests_in_flight gauge\n")
+	// The metrics request itself is in flight.
+	assert.Contains(t, body, `test_http_requests_in_flight{method="GET",route="/metrics"} 1`+"\n")
+	assert.Contains(t, body, `test_http_requests_in_flight{method="GET",route="/item/:id"} 0`+"\n")
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\nd`, escapeLabel("a\\b\"c\nd"))
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "2xx", statusClass(http.StatusOK))
+	assert.Equal(t, "4xx", statusClass(http.StatusNotFound))
+	assert.Equal(t, "5xx", statusClass(http.StatusBadGateway))
+	assert.Equal(t, "0", statusClass(0))
+}
+
+func TestMethodLabel(t *testing.T) {
+	assert.Equal(t, http.MethodGet, methodLabel(http.MethodGet))
+	assert.Equal(t, http.MethodDelete, methodLabel(http.MethodDelete))
+	assert.Equal(t, "OTHER", methodLabel("get"))
+	assert.Equal(t, "OTHER", methodLabel("PROPFIND"))
+}
@@ -0,0 +1,36 @@
+// Package metrics provides [gin] middleware to collect request metrics
+// and a handler to serve them in [Prometheus] text exposition format
+// without requiring an external metrics client library.
+//
+// [gin]: https://github.com/gin-gonic/gin
+// [Prometheus]: https://prometheus.io/docs/instrumenting/exposition_formats/
+//
+// # Import Packages
+//
+//  import (
+//      "github.com/gin-gonic/gin"
+//      "github.com/madkins23/gin-utils/pkg/metrics"
+//  )
+//
+// # Configure Router
+//
+//  collector := metrics.NewCollector(metrics.Options{Namespace: "myapp"})
+//  router := gin.New()
+//  router.Use(collector.Middleware())
+//  router.GET("/metrics", collector.Handler)
+//
+// Use the middleware before the routing calls it is to measure.
+//
+// # Metrics
+//
+// The following metrics are collected, labeled by method ("OTHER" for non-standard methods), route template
+// (gin.Context.FullPath(), or "unmatched" for requests that match no route)
+// and status class (e.g. "2xx"):
+//
+//   - http_requests_total (counter)
+//   - http_request_duration_seconds (histogram)
+//   - http_response_size_bytes (histogram)
+//
+// The http_requests_in_flight gauge is labeled by method and route only.
+// All metric names are prefixed by the Namespace option if it is provided.
+package metrics
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// contentType for Prometheus text exposition format version 0.0.4.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// writeFamilies writes the specified metric families in text exposition format.
+func writeFamilies(writer io.Writer, families []family) error {
+	var out strings.Builder
+	for _, f := range families {
+		f.write(&out)
+	}
+	_, err := io.WriteString(writer, out.String())
+	return err
+}
+
+// writeHeader writes the HELP and TYPE lines for a metric family.
+func writeHeader(out *strings.Builder, name, help, metricType string) {
+	out.WriteString("# HELP " + name + " " + escapeHelp(help) + "\n")
+	out.WriteString("# TYPE " + name + " " + metricType + "\n")
+}
+
+// writeSample writes a single sample line.
+// The key contains the label values for the labels in the series.
+// An extra label (e.g. "le" for histogram buckets) is added if extraName is not empty.
+func writeSample(out *strings.Builder, name string, labels []string, key, extraName, extraValue string, value float64) {
+	out.WriteString(name)
+	values := strings.Split(key, "\xff")
+	if len(labels) > 0 || extraName != "" {
+		out.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(label + `="` + escapeLabel(values[i]) + `"`)
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString(extraName + `="` + extraValue + `"`)
+		}
+		out.WriteByte('}')
+	}
+	out.WriteByte(' ')
+	out.WriteString(formatFloat(value))
+	out.WriteByte('\n')
+}
+
+// formatFloat formats a sample value as required by the exposition format.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes backslash and newline characters in HELP text.
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// escapeLabel escapes backslash, newline, and double quote characters in label values.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
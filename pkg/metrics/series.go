@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// family is a named group of metric series with the same labels.
+type family interface {
+	// write the family in text exposition format to the specified builder.
+	write(out *strings.Builder)
+}
+
+// vec holds the parts common to all metric families.
+type vec struct {
+	name   string
+	help   string
+	labels []string
+	lock   sync.Mutex
+}
+
+// key returns a map key for the specified label values.
+func (v *vec) key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// sortedKeys returns the map keys in sorted order for consistent output.
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// counterVec is a family of counters.
+type counterVec struct {
+	vec
+	series map[string]uint64
+}
+
+func newCounterVec(name, help string, labels []string) *counterVec {
+	return &counterVec{
+		vec:    vec{name: name, help: help, labels: labels},
+		series: make(map[string]uint64),
+	}
+}
+
+// inc increments the counter with the specified label values.
+func (cv *counterVec) inc(values ...string) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+	cv.series[cv.key(values)]++
+}
+
+func (cv *counterVec) write(out *strings.Builder) {
+	cv.lock.Lock()
+	defer cv.lock.Unlock()
+	writeHeader(out, cv.name, cv.help, "counter")
+	for _, key := range sortedKeys(cv.series) {
+		writeSample(out, cv.name, cv.labels, key, "", "", float64(cv.series[key]))
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// gaugeVec is a family of gauges.
+type gaugeVec struct {
+	vec
+	series map[string]float64
+}
+
+func newGaugeVec(name, help string, labels []string) *gaugeVec {
+	return &gaugeVec{
+		vec:    vec{name: name, help: help, labels: labels},
+		series: make(map[string]float64),
+	}
+}
+
+// add the specified delta to the gauge with the specified label values.
+func (gv *gaugeVec) add(delta float64, values ...string) {
+	gv.lock.Lock()
+	defer gv.lock.Unlock()
+	gv.series[gv.key(values)] += delta
+}
+
+func (gv *gaugeVec) write(out *strings.Builder) {
+	gv.lock.Lock()
+	defer gv.lock.Unlock()
+	writeHeader(out, gv.name, gv.help, "gauge")
+	for _, key := range sortedKeys(gv.series) {
+		writeSample(out, gv.name, gv.labels, key, "", "", gv.series[key])
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////
+
+// histogram holds the observations for a single histogram series.
+type histogram struct {
+	counts []uint64 // non-cumulative count for each bucket
+	count  uint64
+	sum    float64
+}
+
+// histogramVec is a family of histograms.
+type histogramVec struct {
+	vec
+	buckets []float64
+	series  map[string]*histogram
+}
+
+func newHistogramVec(name, help string, labels []string, buckets []float64) *histogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &histogramVec{
+		vec:     vec{name: name, help: help, labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogram),
+	}
+}
+
+// observe adds a single observation to the histogram with the specified label values.
+func (hv *histogramVec) observe(value float64, values ...string) {
+	hv.lock.Lock()
+	defer hv.lock.Unlock()
+	key := hv.key(values)
+	h, found := hv.series[key]
+	if !found {
+		h = &histogram{counts: make([]uint64, len(hv.buckets))}
+		hv.series[key] = h
+	}
+	if i := sort.SearchFloat64s(hv.buckets, value); i < len(hv.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += value
+}
+
+func (hv *histogramVec) write(out *strings.Builder) {
+	hv.lock.Lock()
+	defer hv.lock.Unlock()
+	writeHeader(out, hv.name, hv.help, "histogram")
+	for _, key := range sortedKeys(hv.series) {
+		h := hv.series[key]
+		var cumulative uint64
+		for i, bound := range hv.buckets {
+			cumulative += h.counts[i]
+			writeSample(out, hv.name+"_bucket", hv.labels, key, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(out, hv.name+"_bucket", hv.labels, key, "le", "+Inf", float64(h.count))
+		writeSample(out, hv.name+"_sum", hv.labels, key, "", "", h.sum)
+		writeSample(out, hv.name+"_count", hv.labels, key, "", "", float64(h.count))
+	}
+}
@@ -25,7 +25,7 @@
 // # Configure Router
 //
 //  router := gin.Default()
-//  router.GET("/exit", handler.Exit)
+//  router.POST("/exit", handler.ExitWithOptions(handler.ExitOptions{}))
 //
 // Actual router configuration will depend on the application.
 //
@@ -9,9 +9,9 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/madkins23/go-utils/log"
 	"github.com/rs/zerolog"
 
+	"github.com/madkins23/gin-utils/pkg/ginzero"
 	"github.com/madkins23/gin-utils/pkg/system"
 )
 
@@ -22,9 +22,10 @@ type Graceful struct {
 	ctxt context.Context
 	stop context.CancelFunc
 	// TODO: Replace this with slog.Logger with all of the fallout that entails.
-	logger zerolog.Logger
-	server *http.Server
-	closed bool
+	logger  zerolog.Logger
+	server  *http.Server
+	closed  bool
+	started time.Time
 }
 
 // Initialize configures the Graceful object.
@@ -32,7 +33,7 @@ func (g *Graceful) Initialize() {
 	// Create context that listens for the interrupt signal from the OS.
 	// NOTE: this code assumes we're running on Linux, it won't work for Apple or Windows.
 	g.ctxt, g.stop = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	g.logger = log.Logger().With().Str("sys", "graceful").Logger()
+	g.logger = ginzero.SysLogger("graceful")
 }
 
 // Serve executes the gin service as defined.
@@ -48,6 +49,8 @@ func (g *Graceful) Serve(router *gin.Engine, port uint) error {
 		Handler: router,
 	}
 
+	g.started = time.Now()
+
 	// Start server in goroutine so shutdown code can run.
 	go func() {
 		if err := g.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -61,6 +64,21 @@ func (g *Graceful) Serve(router *gin.Engine, port uint) error {
 	return nil
 }
 
+// Started returns the time at which Serve() started the server,
+// or the zero time if the server has not been started.
+func (g *Graceful) Started() time.Time {
+	return g.started
+}
+
+// Uptime returns the time since Serve() started the server,
+// or zero if the server has not been started.
+func (g *Graceful) Uptime() time.Duration {
+	if g.started.IsZero() {
+		return 0
+	}
+	return time.Since(g.started)
+}
+
 // Close the Graceful object, stopping signal capture.
 func (g *Graceful) Close() {
 	if !g.closed {
@@ -65,6 +65,8 @@ func TestGraceful_Serve(t *testing.T) {
 			"message": "pong",
 		})
 	})
+	require.True(t, g.Started().IsZero())
+	require.Zero(t, g.Uptime())
 	go func() { require.NoError(t, g.Serve(router, 8080)) }()
 
 	// Wait for router to respond properly.
@@ -76,7 +78,8 @@ func TestGraceful_Serve(t *testing.T) {
 		g.logger.Error().Err(err).Msg("Server forced to shutdown")
 	}
 
-	require.True(t, true)
+	require.False(t, g.Started().IsZero())
+	require.Positive(t, g.Uptime())
 }
 
 func TestGraceful_Close(t *testing.T) {
@@ -6,17 +6,31 @@ import (
 
 // Config collects all gin configuration information.
 //
-// At the current time there is only one configuration item: port
+// Configuration items:
+//   - port: server port number
+//   - debug: enable runtime debug and profiling endpoints (see handler.RegisterDebugRoutes)
 //
 // This struct has been configured with JSON and YAML struct tags.
 type Config struct {
-	Port uint `json:"port" yaml:"port"`
+	Port  uint `json:"port" yaml:"port"`
+	Debug bool `json:"debug" yaml:"debug"`
 }
 
 // AddFlagsToSet adds flags to the specified flag.FlagSet.
 // Each flag is connected to a field in the configuration object.
 func (cfg *Config) AddFlagsToSet(flags *flag.FlagSet) {
 	flags.UintVar(&cfg.Port, "port", defaultUint(8080, cfg.Port), "specify server port number")
+	flags.BoolVar(&cfg.Debug, "debug", cfg.Debug, "enable runtime debug and profiling endpoints")
+}
+
+// Info returns configuration values that are safe to report,
+// for example via handler.Info.
+// Secret values must never be added here.
+func (cfg *Config) Info() map[string]any {
+	return map[string]any{
+		"port":  cfg.Port,
+		"debug": cfg.Debug,
+	}
 }
 
 func defaultUint(dflt, cfg uint) uint {
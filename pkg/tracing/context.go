@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Header names defined by the W3C Trace Context specification.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+)
+
+// TraceID is a 16 byte W3C trace identifier.
+type TraceID [16]byte
+
+// String returns the trace ID as 32 lowercase hex characters.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid returns true if the trace ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID is an 8 byte W3C span identifier.
+type SpanID [8]byte
+
+// String returns the span ID as 16 lowercase hex characters.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid returns true if the span ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// FlagSampled is the trace flag that indicates the trace is sampled.
+const FlagSampled byte = 0x01
+
+// SpanContext is the portion of a span that is propagated between services.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Flags      byte
+	TraceState string
+	// Remote is true if the span context was parsed from an incoming request.
+	Remote bool
+}
+
+// IsValid returns true if both the trace and span IDs are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// IsSampled returns true if the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the traceparent header value for the span context.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses a traceparent header value
+// and returns the span context it represents with Remote set to true.
+// The tracestate header value (which may be empty) is stored in the result.
+func ParseTraceparent(traceparent, tracestate string) (SpanContext, error) {
+	var sc SpanContext
+	traceparent = strings.TrimSpace(traceparent)
+	if len(traceparent) < 55 {
+		return sc, fmt.Errorf("traceparent too short")
+	}
+	version, err := parseHex(traceparent[0:2])
+	if err != nil {
+		return sc, fmt.Errorf("traceparent version: %w", err)
+	} else if version[0] == 0xff {
+		return sc, fmt.Errorf("traceparent version ff is invalid")
+	} else if version[0] == 0 && len(traceparent) != 55 {
+		return sc, fmt.Errorf("traceparent version 00 has wrong length")
+	} else if len(traceparent) > 55 && traceparent[55] != '-' {
+		// Future versions may append fields after a dash.
+		return sc, fmt.Errorf("traceparent has bad trailing data")
+	}
+	if traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return sc, fmt.Errorf("traceparent delimiters")
+	}
+	if traceID, err := parseHex(traceparent[3:35]); err != nil {
+		return sc, fmt.Errorf("traceparent trace ID: %w", err)
+	} else {
+		copy(sc.TraceID[:], traceID)
+	}
+	if spanID, err := parseHex(traceparent[36:52]); err != nil {
+		return sc, fmt.Errorf("traceparent span ID: %w", err)
+	} else {
+		copy(sc.SpanID[:], spanID)
+	}
+	if flags, err := parseHex(traceparent[53:55]); err != nil {
+		return sc, fmt.Errorf("traceparent flags: %w", err)
+	} else {
+		sc.Flags = flags[0]
+	}
+	if !sc.IsValid() {
+		return sc, fmt.Errorf("traceparent has zero trace or span ID")
+	}
+	if len(tracestate) <= 512 {
+		// Longer values may be truncated but dropping them is also allowed.
+		sc.TraceState = strings.TrimSpace(tracestate)
+	}
+	sc.Remote = true
+	return sc, nil
+}
+
+// parseHex parses lowercase hex characters.
+func parseHex(text string) ([]byte, error) {
+	if strings.ToLower(text) != text {
+		return nil, fmt.Errorf("uppercase hex characters")
+	}
+	return hex.DecodeString(text)
+}
+
+// newTraceID returns a random trace ID.
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// newSpanID returns a random span ID.
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,40 @@
+// Package tracing provides [gin] middleware for distributed tracing
+// using [W3C Trace Context] traceparent and tracestate headers.
+//
+// Spans follow the [OpenTelemetry] data model closely enough
+// that an Exporter can forward them to an OpenTelemetry collector,
+// but no external tracing library is required.
+// The InMemoryExporter is provided for testing.
+//
+// [gin]: https://github.com/gin-gonic/gin
+// [OpenTelemetry]: https://opentelemetry.io/
+// [W3C Trace Context]: https://www.w3.org/TR/trace-context/
+//
+// # Import Packages
+//
+//  import (
+//      "github.com/gin-gonic/gin"
+//      "github.com/madkins23/gin-utils/pkg/ginzero"
+//      "github.com/madkins23/gin-utils/pkg/tracing"
+//  )
+//
+// # Configure Router
+//
+//  tracer := tracing.NewTracer(exporter)
+//  router := gin.New()
+//  router.Use(ginzero.Logger())
+//  router.Use(tracer.Middleware())
+//
+// Use the tracing middleware after ginzero.Logger so that
+// the trace_id and span_id fields are added to the request log record.
+//
+// # Downstream Calls
+//
+// The span for the current request is stored on the request context.
+// Create child spans and propagate the trace to downstream services:
+//
+//  ctxt, span := tracer.StartSpan(c.Request.Context(), "lookup")
+//  defer span.End()
+//  request, _ := http.NewRequestWithContext(ctxt, http.MethodGet, url, nil)
+//  tracing.Inject(ctxt, request.Header)
+package tracing
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"sync"
+)
+
+// Exporter receives spans when they end.
+// Implementations may forward spans to an OpenTelemetry collector or other tracing backend.
+// Export is called synchronously when a span ends so it should not block.
+type Exporter interface {
+	Export(span *Span)
+}
+
+// Make sure the InMemoryExporter struct implements Exporter.
+var _ = Exporter(&InMemoryExporter{})
+
+// InMemoryExporter collects exported spans in memory for use in tests.
+type InMemoryExporter struct {
+	spans []*Span
+	lock  sync.Mutex
+}
+
+// Export adds the span to the exported spans.
+func (ime *InMemoryExporter) Export(span *Span) {
+	ime.lock.Lock()
+	defer ime.lock.Unlock()
+	ime.spans = append(ime.spans, span)
+}
+
+// Spans returns the spans exported so far in the order they ended.
+func (ime *InMemoryExporter) Spans() []*Span {
+	ime.lock.Lock()
+	defer ime.lock.Unlock()
+	return append([]*Span(nil), ime.spans...)
+}
+
+// Reset removes all exported spans.
+func (ime *InMemoryExporter) Reset() {
+	ime.lock.Lock()
+	defer ime.lock.Unlock()
+	ime.spans = nil
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+// StatusCode of a span as defined by OpenTelemetry.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// String returns the OpenTelemetry name of the status code.
+func (sc StatusCode) String() string {
+	switch sc {
+	case StatusOK:
+		return "Ok"
+	case StatusError:
+		return "Error"
+	default:
+		return "Unset"
+	}
+}
+
+// SpanKind of a span as defined by OpenTelemetry.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+)
+
+// Event is a time-stamped annotation on a span, such as a recorded error.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]any
+}
+
+// Span represents a single operation within a trace.
+// Spans are exported when End is called if the span is sampled.
+// Only read span fields after the span has ended.
+type Span struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanContext
+	StartTime     time.Time
+	EndTime       time.Time
+	Status        StatusCode
+	StatusMessage string
+	Attributes    map[string]any
+	Events        []Event
+
+	exporter Exporter
+	ended    bool
+	lock     sync.Mutex
+}
+
+// SetAttribute sets a single attribute on the span.
+func (s *Span) SetAttribute(key string, value any) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]any)
+	}
+	s.Attributes[key] = value
+}
+
+// SetStatus sets the span status and message.
+// The message is only recorded for StatusError.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Status = code
+	if code == StatusError {
+		s.StatusMessage = message
+	}
+}
+
+// RecordError adds an "exception" event for the specified error to the span.
+// The span status is not changed.
+func (s *Span) RecordError(err error, attributes map[string]any) {
+	if err == nil {
+		return
+	}
+	attrs := map[string]any{"exception.message": err.Error()}
+	for key, value := range attributes {
+		attrs[key] = value
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Events = append(s.Events, Event{Name: "exception", Time: time.Now(), Attributes: attrs})
+}
+
+// End the span and export it if it is sampled.
+// Calling End more than once has no effect.
+func (s *Span) End() {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.lock.Unlock()
+	if s.exporter != nil && s.SpanContext.IsSampled() {
+		s.exporter.Export(s)
+	}
+}
@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/madkins23/gin-utils/pkg/ginzero"
+)
+
+// Tracer creates spans and provides the tracing middleware.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a Tracer that sends ended spans to the specified Exporter.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// spanKey is the context key for the current span.
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of the context containing the specified span.
+func ContextWithSpan(ctxt context.Context, span *Span) context.Context {
+	return context.WithValue(ctxt, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span from the context or nil if there is none.
+func SpanFromContext(ctxt context.Context) *Span {
+	if span, ok := ctxt.Value(spanKey{}).(*Span); ok {
+		return span
+	}
+	return nil
+}
+
+// StartSpan starts a new span as a child of the span in the context, if any.
+// The returned context contains the new span.
+// Call End on the span when the operation is complete.
+func (t *Tracer) StartSpan(ctxt context.Context, name string) (context.Context, *Span) {
+	var parent SpanContext
+	if span := SpanFromContext(ctxt); span != nil {
+		parent = span.SpanContext
+	}
+	span := t.newSpan(name, SpanKindInternal, parent)
+	return ContextWithSpan(ctxt, span), span
+}
+
+// newSpan creates a span with the specified parent (which may be invalid for a root span).
+// Root spans are sampled, child spans follow the sampling decision of the parent.
+func (t *Tracer) newSpan(name string, kind SpanKind, parent SpanContext) *Span {
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Flags = FlagSampled
+	}
+	return &Span{
+		Name:        name,
+		Kind:        kind,
+		SpanContext: sc,
+		Parent:      parent,
+		StartTime:   time.Now(),
+		exporter:    t.exporter,
+	}
+}
+
+// Inject adds traceparent and tracestate headers for the span in the context
+// to the specified header, usually for a downstream request.
+// Nothing is added if there is no span in the context.
+func Inject(ctxt context.Context, header http.Header) {
+	if span := SpanFromContext(ctxt); span != nil {
+		header.Set(HeaderTraceparent, span.SpanContext.Traceparent())
+		if span.SpanContext.TraceState != "" {
+			header.Set(HeaderTracestate, span.SpanContext.TraceState)
+		}
+	}
+}
+
+// Middleware returns a gin middleware function that creates a server span for each request.
+//
+// The span continues the trace from any incoming traceparent header and is named
+// after the request method and the gin route template.
+// The span is stored on the request context and the trace and span IDs are
+// stored on the gin.Context for use by ginzero.Logger.
+// The traceparent and tracestate headers for the span are added to the response.
+func (t *Tracer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var parent SpanContext
+		if traceparent := c.GetHeader(HeaderTraceparent); traceparent != "" {
+			var err error
+			if parent, err = ParseTraceparent(traceparent, c.GetHeader(HeaderTracestate)); err != nil {
+				log.Debug().Err(err).Str("traceparent", traceparent).Msg("Ignoring traceparent")
+				parent = SpanContext{}
+			}
+		}
+
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		span := t.newSpan(name, SpanKindServer, parent)
+		span.SetAttribute("http.request.method", c.Request.Method)
+		span.SetAttribute("url.path", c.Request.URL.Path)
+		span.SetAttribute("client.address", c.ClientIP())
+		if route != "" {
+			span.SetAttribute("http.route", route)
+		}
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ContextWithSpan(c.Request.Context(), span))
+		c.Set(ginzero.TraceIDKey, span.SpanContext.TraceID.String())
+		c.Set(ginzero.SpanIDKey, span.SpanContext.SpanID.String())
+		Inject(c.Request.Context(), c.Writer.Header())
+
+		c.Next()
+
+		code := c.Writer.Status()
+		span.SetAttribute("http.response.status_code", code)
+		for _, err := range c.Errors {
+			span.RecordError(err.Err, map[string]any{"gin.error.meta": err.Meta})
+		}
+		if code >= 500 {
+			span.SetStatus(StatusError, http.StatusText(code))
+		} else if private := c.Errors.ByType(gin.ErrorTypePrivate); len(private) > 0 {
+			span.SetStatus(StatusError, private.Last().Error())
+		}
+	}
+}
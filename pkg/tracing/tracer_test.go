@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/madkins23/gin-utils/pkg/ginzero"
+)
+
+func TestTracer_Middleware(t *testing.T) {
+	zLog := log.Logger
+	defer func() { log.Logger = zLog }()
+	buffer := &bytes.Buffer{}
+	log.Logger = zerolog.New(buffer)
+
+	exporter := &InMemoryExporter{}
+	tracer := NewTracer(exporter)
+	router := gin.New()
+	router.Use(ginzero.Logger())
+	router.Use(tracer.Middleware())
+	var downstream http.Header
+	router.GET("/item/:id", func(c *gin.Context) {
+		ctxt, span := tracer.StartSpan(c.Request.Context(), "lookup")
+		defer span.End()
+		downstream = http.Header{}
+		Inject(ctxt, downstream)
+		_ = c.Error(errors.New("lookup failed"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/item/23", nil)
+	request.Header.Set(HeaderTraceparent, testTraceparent)
+	request.Header.Set(HeaderTracestate, "congo=t61rcWkgMzE")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, request)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+	assert.Equal(t, "GET /item/:id", server.Name)
+	assert.Equal(t, SpanKindServer, server.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID.String())
+	assert.True(t, server.Parent.Remote)
+	assert.Equal(t, "/item/:id", server.Attributes["http.route"])
+	assert.Equal(t, http.StatusInternalServerError, server.Attributes["http.response.status_code"])
+	assert.Equal(t, StatusError, server.Status)
+	require.Len(t, server.Events, 1)
+	assert.Equal(t, "lookup failed", server.Events[0].Attributes["exception.message"])
+	assert.False(t, server.EndTime.IsZero())
+
+	assert.Equal(t, "lookup", child.Name)
+	assert.Equal(t, server.SpanContext.TraceID, child.SpanContext.TraceID)
+	assert.Equal(t, server.SpanContext.SpanID, child.Parent.SpanID)
+	assert.Equal(t, child.SpanContext.Traceparent(), downstream.Get(HeaderTraceparent))
+	assert.Equal(t, "congo=t61rcWkgMzE", downstream.Get(HeaderTracestate))
+
+	assert.Equal(t, server.SpanContext.Traceparent(), rec.Header().Get(HeaderTraceparent))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &record))
+	assert.Equal(t, server.SpanContext.TraceID.String(), record["trace_id"])
+	assert.Equal(t, server.SpanContext.SpanID.String(), record["span_id"])
+}
+
+func TestTracer_Middleware_PrivateError(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	router := gin.New()
+	router.Use(NewTracer(exporter).Middleware())
+	router.GET("/item", func(c *gin.Context) {
+		_ = c.Error(errors.New("cache offline"))
+		_ = c.Error(errors.New("bad item id")).SetType(gin.ErrorTypePublic)
+		c.Status(http.StatusBadRequest)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/item", nil))
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, StatusError, spans[0].Status)
+	assert.Equal(t, "cache offline", spans[0].StatusMessage)
+	assert.Len(t, spans[0].Events, 2)
+}
+
+func TestTracer_Middleware_Root(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	router := gin.New()
+	router.Use(NewTracer(exporter).Middleware())
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Unsampled parent is not exported.
+	request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	request.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Empty(t, exporter.Spans())
+
+	// Bad traceparent starts a new root span.
+	request = httptest.NewRequest(http.MethodGet, "/ping", nil)
+	request.Header.Set(HeaderTraceparent, "garbage")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.True(t, spans[0].SpanContext.IsSampled())
+	assert.Equal(t, StatusUnset, spans[0].Status)
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}